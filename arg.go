@@ -1,15 +1,21 @@
 package cli
 
 import (
+	"io"
 	"net/url"
 	"time"
 )
 
 type Arg struct {
-	name  string
-	help  string
-	value value
-	env   *string
+	name   string
+	help   string
+	value  value
+	env    *env
+	config *config
+	// omitted from the help
+	hidden bool
+	// deprecation message
+	deprecated *string
 }
 
 func (a *Arg) key() string {
@@ -18,7 +24,13 @@ func (a *Arg) key() string {
 
 // Env allows you to use an environment variable to set the value of the argument.
 func (a *Arg) Env(name string) *Arg {
-	a.env = &name
+	a.env.name = name
+	return a
+}
+
+// Hidden omits the argument from the help. Hidden arguments are still parsed.
+func (a *Arg) Hidden() *Arg {
+	a.hidden = true
 	return a
 }
 
@@ -27,55 +39,71 @@ func (a *Arg) Optional() *OptionalArg {
 }
 
 func (a *Arg) Int(target *int) *Int {
-	value := &Int{target, a.env, nil}
+	value := &Int{target: target, envvar: a.env}
 	a.value = &intValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Duration(target *time.Duration) *Duration {
-	value := &Duration{target, a.env, nil}
+	value := &Duration{target: target, envvar: a.env}
 	a.value = &durationValue{key: a.key(), inner: value}
 	return value
 }
 
+// Bytes accepts a byte size like 10MB, 512KiB or 1G, where single letter units
+// are IEC units.
+func (a *Arg) Bytes(target *int64) *Bytes {
+	value := &Bytes{target: target, envvar: a.env}
+	a.value = &bytesValue{key: a.key(), inner: value}
+	return value
+}
+
+// Time accepts RFC3339 times, dates like 2006-01-02, the custom layouts, "now"
+// and times relative to now like 2h, -3d or +1w.
+func (a *Arg) Time(target *time.Time, layouts ...string) *Time {
+	value := &Time{target: target, envvar: a.env}
+	a.value = &timeValue{key: a.key(), inner: value, config: a.config, layouts: layouts}
+	return value
+}
+
 func (a *Arg) Url(target *url.URL) *Url {
-	value := &Url{target, a.env, nil}
+	value := &Url{target: target, envvar: a.env}
 	a.value = &urlValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Int64(target *int64) *Int64 {
-	value := &Int64{target, a.env, nil}
+	value := &Int64{target: target, envvar: a.env}
 	a.value = &int64Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Float32(target *float32) *Float32 {
-	value := &Float32{target, a.env, nil}
+	value := &Float32{target: target, envvar: a.env}
 	a.value = &float32Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Float64(target *float64) *Float64 {
-	value := &Float64{target, a.env, nil}
+	value := &Float64{target: target, envvar: a.env}
 	a.value = &float64Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Bool(target *bool) *Bool {
-	value := &Bool{target, a.env, nil}
+	value := &Bool{target: target, envvar: a.env}
 	a.value = &boolValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) String(target *string) *String {
-	value := &String{target, a.env, nil}
+	value := &String{target: target, envvar: a.env}
 	a.value = &stringValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *Arg) Enum(target *string, possibilities ...string) *Enum {
-	value := &Enum{target, a.env, nil}
+	value := &Enum{target: target, envvar: a.env}
 	a.value = &enumValue{key: a.key(), inner: value, possibilities: possibilities}
 	return value
 }
@@ -83,11 +111,39 @@ func (a *Arg) Enum(target *string, possibilities ...string) *Enum {
 // StringMap accepts a key-value pair in the form of "<key:value>".
 func (a *Arg) StringMap(target *map[string]string) *StringMap {
 	*target = map[string]string{}
-	value := &StringMap{target, a.env, nil, false}
+	value := &StringMap{target: target, envvar: a.env}
 	a.value = &stringMapValue{key: "<key:value>", inner: value}
 	return value
 }
 
+// File opens the file for reading, or reads stdin when the path is "-".
+func (a *Arg) File(target *io.ReadCloser) *File {
+	value := &File{target: target, envvar: a.env}
+	a.value = &fileReaderValue{key: a.key(), inner: value, config: a.config}
+	return value
+}
+
+// Path accepts any path and expands a leading "~" to the home directory.
+func (a *Arg) Path(target *string) *Path {
+	value := &Path{target: target, envvar: a.env}
+	a.value = &pathValue{key: a.key(), kind: anyPath, inner: value}
+	return value
+}
+
+// Dir is a path to a directory that must exist.
+func (a *Arg) Dir(target *string) *Path {
+	value := &Path{target: target, envvar: a.env}
+	a.value = &pathValue{key: a.key(), kind: dirPath, inner: value}
+	return value
+}
+
+// ExistingFile is a path to a file that must exist.
+func (a *Arg) ExistingFile(target *string) *Path {
+	value := &Path{target: target, envvar: a.env}
+	a.value = &pathValue{key: a.key(), kind: filePath, inner: value}
+	return value
+}
+
 func (a *Arg) verify() error {
 	return a.value.verify()
 }
@@ -101,69 +157,81 @@ func (a *OptionalArg) key() string {
 }
 
 func (a *OptionalArg) String(target **string) *OptionalString {
-	value := &OptionalString{target, a.a.env, nil}
+	value := &OptionalString{target: target, envvar: a.a.env}
 	a.a.value = &optionalStringValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Int(target **int) *OptionalInt {
-	value := &OptionalInt{target, a.a.env, nil}
+	value := &OptionalInt{target: target, envvar: a.a.env}
 	a.a.value = &optionalIntValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Duration(target **time.Duration) *OptionalDuration {
-	value := &OptionalDuration{target, a.a.env, nil}
+	value := &OptionalDuration{target: target, envvar: a.a.env}
 	a.a.value = &optionalDurationValue{key: a.key(), inner: value}
 	return value
 }
 
+func (a *OptionalArg) Bytes(target **int64) *OptionalBytes {
+	value := &OptionalBytes{target: target, envvar: a.a.env}
+	a.a.value = &optionalBytesValue{key: a.key(), inner: value}
+	return value
+}
+
+func (a *OptionalArg) Time(target **time.Time, layouts ...string) *OptionalTime {
+	value := &OptionalTime{target: target, envvar: a.a.env}
+	a.a.value = &optionalTimeValue{key: a.key(), inner: value, config: a.a.config, layouts: layouts}
+	return value
+}
+
 func (a *OptionalArg) Url(target **url.URL) *OptionalUrl {
-	value := &OptionalUrl{target, a.a.env, nil}
+	value := &OptionalUrl{target: target, envvar: a.a.env}
 	a.a.value = &optionalUrlValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Int64(target **int64) *OptionalInt64 {
-	value := &OptionalInt64{target, a.a.env, nil}
+	value := &OptionalInt64{target: target, envvar: a.a.env}
 	a.a.value = &optionalInt64Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Float32(target **float32) *OptionalFloat32 {
-	value := &OptionalFloat32{target, a.a.env, nil}
+	value := &OptionalFloat32{target: target, envvar: a.a.env}
 	a.a.value = &optionalFloat32Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Float64(target **float64) *OptionalFloat64 {
-	value := &OptionalFloat64{target, a.a.env, nil}
+	value := &OptionalFloat64{target: target, envvar: a.a.env}
 	a.a.value = &optionalFloat64Value{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Bool(target **bool) *OptionalBool {
-	value := &OptionalBool{target, a.a.env, nil}
+	value := &OptionalBool{target: target, envvar: a.a.env}
 	a.a.value = &optionalBoolValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArg) Enum(target **string, possibilities ...string) *OptionalEnum {
-	value := &OptionalEnum{target, a.a.env, nil}
+	value := &OptionalEnum{target: target, envvar: a.a.env}
 	a.a.value = &optionalEnumValue{key: a.key(), inner: value, possibilities: possibilities}
 	return value
 }
 
 func (a *OptionalArg) StringMap(target *map[string]string) *StringMap {
 	*target = map[string]string{}
-	value := &StringMap{target, a.a.env, nil, true}
+	value := &StringMap{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &stringMapValue{key: a.key(), inner: value}
 	return value
 }
 
-func verifyArgs(args []*Arg) error {
+func verifyArgs(args []*Arg, p *prompter) error {
 	for _, arg := range args {
-		if err := arg.verify(); err != nil {
+		if err := p.ask(arg.verify(), arg.key(), arg.help, arg.value, false, false); err != nil {
 			return err
 		}
 	}
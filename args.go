@@ -6,10 +6,11 @@ import (
 )
 
 type Args struct {
-	name  string
-	help  string
-	value value
-	env   *string
+	name   string
+	help   string
+	value  value
+	env    *env
+	config *config
 }
 
 func (a *Args) key() string {
@@ -22,7 +23,7 @@ func (a *Args) verify() error {
 
 // Env allows you to use an environment variable to set the value of the argument.
 func (a *Args) Env(name string) *Args {
-	a.env = &name
+	a.env.name = name
 	return a
 }
 
@@ -34,7 +35,7 @@ func (a *Args) Strings(target *[]string) *Strings {
 	if target != nil {
 		*target = []string{}
 	}
-	value := &Strings{target, a.env, nil, false}
+	value := &Strings{target: target, envvar: a.env}
 	a.value = &stringsValue{key: a.key(), inner: value}
 	return value
 }
@@ -43,7 +44,7 @@ func (a *Args) Urls(target *[]*url.URL) *Urls {
 	if target != nil {
 		*target = []*url.URL{}
 	}
-	value := &Urls{target, a.env, nil, false}
+	value := &Urls{target: target, envvar: a.env}
 	a.value = &urlsValue{key: a.key(), inner: value}
 	return value
 }
@@ -61,16 +62,36 @@ func (a *Args) Durations(target *[]time.Duration) *Durations {
 	if target != nil {
 		*target = []time.Duration{}
 	}
-	value := &Durations{target, a.env, nil, false}
+	value := &Durations{target: target, envvar: a.env}
 	a.value = &durationsValue{key: a.key(), inner: value}
 	return value
 }
 
+// ByteSizes accepts byte sizes like 10MB, 512KiB or 1G.
+func (a *Args) ByteSizes(target *[]int64) *ByteSizes {
+	if target != nil {
+		*target = []int64{}
+	}
+	value := &ByteSizes{target: target, envvar: a.env}
+	a.value = &byteSizesValue{key: a.key(), inner: value}
+	return value
+}
+
+// Times accepts the same values as Arg.Time.
+func (a *Args) Times(target *[]time.Time, layouts ...string) *Times {
+	if target != nil {
+		*target = []time.Time{}
+	}
+	value := &Times{target: target, envvar: a.env}
+	a.value = &timesValue{key: a.key(), inner: value, config: a.config, layouts: layouts}
+	return value
+}
+
 func (a *Args) Int64s(target *[]int64) *Int64s {
 	if target != nil {
 		*target = []int64{}
 	}
-	value := &Int64s{target, a.env, nil, false}
+	value := &Int64s{target: target, envvar: a.env}
 	a.value = &int64sValue{key: a.key(), inner: value}
 	return value
 }
@@ -79,7 +100,7 @@ func (a *Args) Float32s(target *[]float32) *Float32s {
 	if target != nil {
 		*target = []float32{}
 	}
-	value := &Float32s{target, a.env, nil, false}
+	value := &Float32s{target: target, envvar: a.env}
 	a.value = &float32sValue{key: a.key(), inner: value}
 	return value
 }
@@ -88,7 +109,7 @@ func (a *Args) Float64s(target *[]float64) *Float64s {
 	if target != nil {
 		*target = []float64{}
 	}
-	value := &Float64s{target, a.env, nil, false}
+	value := &Float64s{target: target, envvar: a.env}
 	a.value = &float64sValue{key: a.key(), inner: value}
 	return value
 }
@@ -97,11 +118,41 @@ func (a *Args) StringMap(target *map[string]string) *StringMap {
 	if target != nil {
 		*target = map[string]string{}
 	}
-	value := &StringMap{target, a.env, nil, false}
+	value := &StringMap{target: target, envvar: a.env}
 	a.value = &stringMapValue{key: "<key:value...>", inner: value}
 	return value
 }
 
+// Paths accepts paths and expands a leading "~" to the home directory.
+func (a *Args) Paths(target *[]string) *Paths {
+	if target != nil {
+		*target = []string{}
+	}
+	value := &Paths{target: target, envvar: a.env}
+	a.value = &pathsValue{key: a.key(), kind: anyPath, inner: value}
+	return value
+}
+
+// Dirs are paths to directories that must exist.
+func (a *Args) Dirs(target *[]string) *Paths {
+	if target != nil {
+		*target = []string{}
+	}
+	value := &Paths{target: target, envvar: a.env}
+	a.value = &pathsValue{key: a.key(), kind: dirPath, inner: value}
+	return value
+}
+
+// ExistingFiles are paths to files that must exist.
+func (a *Args) ExistingFiles(target *[]string) *Paths {
+	if target != nil {
+		*target = []string{}
+	}
+	value := &Paths{target: target, envvar: a.env}
+	a.value = &pathsValue{key: a.key(), kind: filePath, inner: value}
+	return value
+}
+
 type OptionalArgs struct {
 	a *Args
 }
@@ -111,13 +162,13 @@ func (a *OptionalArgs) key() string {
 }
 
 func (a *OptionalArgs) Strings(target *[]string) *Strings {
-	value := &Strings{target, a.a.env, nil, true}
+	value := &Strings{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &stringsValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArgs) Urls(target *[]*url.URL) *Urls {
-	value := &Urls{target, a.a.env, nil, true}
+	value := &Urls{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &urlsValue{key: a.key(), inner: value}
 	return value
 }
@@ -132,31 +183,64 @@ func (a *OptionalArgs) Durations(target *[]time.Duration) *Durations {
 	if target != nil {
 		*target = []time.Duration{}
 	}
-	value := &Durations{target, a.a.env, nil, true}
+	value := &Durations{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &durationsValue{key: a.key(), inner: value}
 	return value
 }
 
+func (a *OptionalArgs) ByteSizes(target *[]int64) *ByteSizes {
+	value := &ByteSizes{target: target, envvar: a.a.env, optional: true}
+	a.a.value = &byteSizesValue{key: a.key(), inner: value}
+	return value
+}
+
+func (a *OptionalArgs) Times(target *[]time.Time, layouts ...string) *Times {
+	value := &Times{target: target, envvar: a.a.env, optional: true}
+	a.a.value = &timesValue{key: a.key(), inner: value, config: a.a.config, layouts: layouts}
+	return value
+}
+
 func (a *OptionalArgs) Int64s(target *[]int64) *Int64s {
-	value := &Int64s{target, a.a.env, nil, true}
+	value := &Int64s{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &int64sValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArgs) Float32s(target *[]float32) *Float32s {
-	value := &Float32s{target, a.a.env, nil, true}
+	value := &Float32s{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &float32sValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArgs) Float64s(target *[]float64) *Float64s {
-	value := &Float64s{target, a.a.env, nil, true}
+	value := &Float64s{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &float64sValue{key: a.key(), inner: value}
 	return value
 }
 
 func (a *OptionalArgs) StringMap(target *map[string]string) *StringMap {
-	value := &StringMap{target, a.a.env, nil, true}
+	value := &StringMap{target: target, envvar: a.a.env, optional: true}
 	a.a.value = &stringMapValue{key: a.key(), inner: value}
 	return value
 }
+
+// Paths accepts paths and expands a leading "~" to the home directory.
+func (a *OptionalArgs) Paths(target *[]string) *Paths {
+	value := &Paths{target: target, envvar: a.a.env, optional: true}
+	a.a.value = &pathsValue{key: a.key(), kind: anyPath, inner: value}
+	return value
+}
+
+// Dirs are paths to directories that must exist.
+func (a *OptionalArgs) Dirs(target *[]string) *Paths {
+	value := &Paths{target: target, envvar: a.a.env, optional: true}
+	a.a.value = &pathsValue{key: a.key(), kind: dirPath, inner: value}
+	return value
+}
+
+// ExistingFiles are paths to files that must exist.
+func (a *OptionalArgs) ExistingFiles(target *[]string) *Paths {
+	value := &Paths{target: target, envvar: a.a.env, optional: true}
+	a.a.value = &pathsValue{key: a.key(), kind: filePath, inner: value}
+	return value
+}
@@ -7,14 +7,32 @@ import (
 
 type Bool struct {
 	target *bool
-	envvar *string
-	defval *bool // default value
+	envvar *env
+	defval *bool  // default value
+	source Source // where the value came from
 }
 
 func (v *Bool) Default(value bool) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Bool) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Bool) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Bool) IsSet() bool {
+	return v.source.isSet()
+}
+
 type boolValue struct {
 	key   string
 	inner *Bool
@@ -27,6 +45,19 @@ func (v *boolValue) optional() bool {
 	return false
 }
 
+func (v *boolValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *boolValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *boolValue) placeholder() string {
+	return ""
+}
+
 func (v *boolValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -41,9 +72,13 @@ func (v *boolValue) Default() (string, bool) {
 func (v *boolValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -77,14 +112,32 @@ func (v *boolValue) IsBoolFlag() bool {
 
 type OptionalBool struct {
 	target **bool
-	envvar *string
-	defval *bool // default value
+	envvar *env
+	defval *bool  // default value
+	source Source // where the value came from
 }
 
 func (v *OptionalBool) Default(value bool) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalBool) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalBool) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalBool) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalBoolValue struct {
 	key   string
 	inner *OptionalBool
@@ -102,6 +155,22 @@ func (v *optionalBoolValue) optional() bool {
 	return true
 }
 
+func (v *optionalBoolValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalBoolValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalBoolValue) placeholder() string {
+	return ""
+}
+
 func (v *optionalBoolValue) Default() (string, bool) {
 	if v.inner.defval == nil {
 		return "", false
@@ -116,9 +185,13 @@ func (v *optionalBoolValue) hasDefault() bool {
 func (v *optionalBoolValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
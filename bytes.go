@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// byteUnits are the SI and IEC units, largest first
+var byteUnits = []struct {
+	name string
+	size int64
+}{
+	{"EiB", 1 << 60},
+	{"EB", 1e18},
+	{"PiB", 1 << 50},
+	{"PB", 1e15},
+	{"TiB", 1 << 40},
+	{"TB", 1e12},
+	{"GiB", 1 << 30},
+	{"GB", 1e9},
+	{"MiB", 1 << 20},
+	{"MB", 1e6},
+	{"KiB", 1 << 10},
+	{"KB", 1e3},
+	{"B", 1},
+}
+
+// shortByteUnits are single letter units, which are IEC units like in ls -h,
+// e.g. 10K is 10KiB
+var shortByteUnits = map[string]int64{
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+	"P": 1 << 50,
+	"E": 1 << 60,
+}
+
+// errNegativeBytes is returned for sizes below zero
+var errNegativeBytes = errors.New("negative byte size")
+
+// parseBytes parses a byte size like "10MB", "512KiB", "1.5GB" or "1G". Units
+// are case-insensitive and numbers without a unit are bytes.
+func parseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "-") {
+		return 0, errNegativeBytes
+	}
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = s[:i], strings.TrimSpace(s[i:])
+	}
+	size := int64(1)
+	if unit != "" {
+		var ok bool
+		if size, ok = byteUnit(unit); !ok {
+			return 0, fmt.Errorf("unknown unit %q", unit)
+		}
+	}
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64/size {
+			return 0, fmt.Errorf("%q is too large", s)
+		}
+		return n * size, nil
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, err
+	}
+	bytes := f * float64(size)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("%q is too large", s)
+	}
+	return int64(bytes), nil
+}
+
+// byteUnit returns the size of the unit, ignoring case
+func byteUnit(unit string) (int64, bool) {
+	if size, ok := shortByteUnits[strings.ToUpper(unit)]; ok {
+		return size, true
+	}
+	for _, u := range byteUnits {
+		if strings.EqualFold(unit, u.name) {
+			return u.size, true
+		}
+	}
+	return 0, false
+}
+
+// bytesError explains which byte sizes are accepted
+func bytesError(key, val string, err error) error {
+	if errors.Is(err, errNegativeBytes) {
+		return fmt.Errorf("%s: expected a byte size that isn't negative but got %q", key, val)
+	}
+	return fmt.Errorf("%s: expected a byte size like 10MB, 512KiB or 1G but got %q", key, val)
+}
+
+// formatBytes formats the size with the largest unit that divides it evenly
+func formatBytes(n int64) string {
+	if n == 0 {
+		return "0B"
+	}
+	for _, u := range byteUnits {
+		if n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+type Bytes struct {
+	target *int64
+	envvar *env
+	defval *int64
+	source Source // where the value came from
+}
+
+func (v *Bytes) Default(value int64) {
+	v.defval = &value
+}
+
+// Source returns where the value came from
+func (v *Bytes) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Bytes) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Bytes) IsSet() bool {
+	return v.source.isSet()
+}
+
+type bytesValue struct {
+	key   string
+	inner *Bytes
+	set   bool
+}
+
+var _ value = (*bytesValue)(nil)
+
+func (v *bytesValue) optional() bool {
+	return false
+}
+
+func (v *bytesValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *bytesValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *bytesValue) placeholder() string {
+	return "size"
+}
+
+func (v *bytesValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *bytesValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	return formatBytes(*v.inner.defval), true
+}
+
+func (v *bytesValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = *v.inner.defval
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *bytesValue) Set(val string) error {
+	n, err := parseBytes(val)
+	if err != nil {
+		return bytesError(v.key, val, err)
+	}
+	*v.inner.target = n
+	v.set = true
+	return nil
+}
+
+func (v *bytesValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatBytes(*v.inner.target)
+	} else if v.hasDefault() {
+		return formatBytes(*v.inner.defval)
+	}
+	return ""
+}
+
+type OptionalBytes struct {
+	target **int64
+	envvar *env
+	defval *int64
+	source Source // where the value came from
+}
+
+func (v *OptionalBytes) Default(value int64) {
+	v.defval = &value
+}
+
+// Source returns where the value came from
+func (v *OptionalBytes) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalBytes) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalBytes) IsSet() bool {
+	return v.source.isSet()
+}
+
+type optionalBytesValue struct {
+	key   string
+	inner *OptionalBytes
+	set   bool
+}
+
+var _ value = (*optionalBytesValue)(nil)
+
+func (v *optionalBytesValue) optional() bool {
+	return true
+}
+
+func (v *optionalBytesValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalBytesValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalBytesValue) placeholder() string {
+	return "size"
+}
+
+func (v *optionalBytesValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *optionalBytesValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	return formatBytes(*v.inner.defval), true
+}
+
+func (v *optionalBytesValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = v.inner.defval
+		return nil
+	}
+	return nil
+}
+
+func (v *optionalBytesValue) Set(val string) error {
+	n, err := parseBytes(val)
+	if err != nil {
+		return bytesError(v.key, val, err)
+	}
+	*v.inner.target = &n
+	v.set = true
+	return nil
+}
+
+func (v *optionalBytesValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatBytes(**v.inner.target)
+	} else if v.hasDefault() {
+		return formatBytes(*v.inner.defval)
+	}
+	return ""
+}
+
+type ByteSizes struct {
+	target   *[]int64
+	envvar   *env
+	defval   *[]int64
+	optional bool
+	source   Source // where the value came from
+}
+
+func (v *ByteSizes) Default(values ...int64) {
+	v.defval = &values
+}
+
+// Source returns where the value came from
+func (v *ByteSizes) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *ByteSizes) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *ByteSizes) IsSet() bool {
+	return v.source.isSet()
+}
+
+type byteSizesValue struct {
+	key   string
+	inner *ByteSizes
+	set   bool
+}
+
+var _ value = (*byteSizesValue)(nil)
+
+func (v *byteSizesValue) optional() bool {
+	return v.inner.optional
+}
+
+func (v *byteSizesValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *byteSizesValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *byteSizesValue) placeholder() string {
+	return "size"
+}
+
+func (v *byteSizesValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		fields, err := shellquote.Split(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a list of byte sizes but got %q", v.key, value)
+		}
+		for _, val := range fields {
+			if err := v.Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = *v.inner.defval
+		return nil
+	} else if v.inner.optional {
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *byteSizesValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *byteSizesValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	if len(*v.inner.defval) == 0 {
+		return "[]", true
+	}
+	return formatByteSizes(*v.inner.defval), true
+}
+
+func (v *byteSizesValue) Set(val string) error {
+	n, err := parseBytes(val)
+	if err != nil {
+		return bytesError(v.key, val, err)
+	}
+	*v.inner.target = append(*v.inner.target, n)
+	v.set = true
+	return nil
+}
+
+func (v *byteSizesValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatByteSizes(*v.inner.target)
+	} else if v.hasDefault() {
+		return formatByteSizes(*v.inner.defval)
+	}
+	return ""
+}
+
+func formatByteSizes(sizes []int64) string {
+	strs := make([]string, len(sizes))
+	for i, n := range sizes {
+		strs[i] = formatBytes(n)
+	}
+	return strings.Join(strs, ", ")
+}
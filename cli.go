@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 )
 
 var ErrInvalidInput = errors.New("cli: invalid input")
@@ -20,36 +21,110 @@ type Middleware = func(next func(ctx context.Context) error) func(ctx context.Co
 
 type Command interface {
 	Command(name, help string) Command
+	Long(text string) Command
 	Alias(name string) Command
 	Hidden() Command
 	Advanced() Command
+	Deprecated(msg string) Command
 	Flag(name, help string) *Flag
+	FlagGroups(names ...string) Command
 	Arg(name, help string) *Arg
 	Args(name, help string) *Args
+	Example(cmdline, help string) Command
 	Use(middlewares ...Middleware) Command
+	UseLocal(middlewares ...Middleware) Command
+	Isolated() Command
 	Run(runner func(ctx context.Context) error)
 }
 
 func New(name, help string) *CLI {
-	config := &config{os.Stdout, defaultUsage, defaultSignals()}
-	return &CLI{newCommand(config, nil, []*Flag{}, name, name, help), config}
+	config := &config{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, usage: defaultUsage, signals: defaultSignals(), theme: DefaultTheme}
+	return &CLI{root: newCommand(config, nil, []*Flag{}, name, name, help), config: config}
 }
 
 type CLI struct {
 	root   *command
 	config *config
+	help   bool
 }
 
 var _ Command = (*CLI)(nil)
 
 type config struct {
-	writer  io.Writer
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
 	usage   *template.Template
 	signals []os.Signal
+	width   int
+	color   ColorMode
+	theme   Theme
+	version *versionConfig
+	// prompt for missing values when stdin is a terminal
+	interactive bool
+	// read $NAME_FILE variants of environment variables
+	envFiles bool
+	// resolves relative times, defaults to time.Now
+	now func() time.Time
+	// read from the clock once per parse
+	started time.Time
+	// set by the optional --color flag
+	colorFlag *string
+	// warnings that were already printed during this parse
+	warned map[string]bool
+	// examples are being verified, so warnings aren't printed
+	verifying bool
 }
 
+// reset the config and the values that are left over from the previous parse
+func (c *CLI) reset() {
+	c.config.reset()
+	c.root.resetValues()
+}
+
+// reset the state that's left over from the previous parse
+func (c *config) reset() {
+	// Each warning is printed once per parse
+	c.warned = map[string]bool{}
+	// --color only applies to the parse it was passed to
+	c.colorFlag = nil
+	// Relative times resolve against the clock at the next parse
+	c.started = time.Time{}
+	if c.version != nil {
+		c.version.requested = false
+	}
+}
+
+// warn prints a warning to stderr once per parse
+func (c *config) warn(msg string) {
+	if c.warned[msg] || c.verifying {
+		return
+	}
+	if c.warned == nil {
+		c.warned = map[string]bool{}
+	}
+	c.warned[msg] = true
+	fmt.Fprintln(c.stderr, paint(true, c.errorStyle(), "warning:")+" "+msg)
+}
+
+// Writer is an alias for Stdout. It used to set the writer for all output, but
+// now only sets stdout. Use Stderr to capture warnings and the usage that's
+// printed because of bad input.
 func (c *CLI) Writer(writer io.Writer) *CLI {
-	c.config.writer = writer
+	return c.Stdout(writer)
+}
+
+// Stdout sets the writer for the requested help, the version and completions,
+// defaults to os.Stdout
+func (c *CLI) Stdout(writer io.Writer) *CLI {
+	c.config.stdout = writer
+	return c
+}
+
+// Stderr sets the writer for warnings and the usage that's shown because of
+// bad input, defaults to os.Stderr
+func (c *CLI) Stderr(writer io.Writer) *CLI {
+	c.config.stderr = writer
 	return c
 }
 
@@ -66,12 +141,13 @@ func (c *CLI) Trap(signals ...os.Signal) *CLI {
 func (c *CLI) Parse(ctx context.Context, args ...string) error {
 	// Trap signals if any were provided
 	ctx = trap(ctx, c.config.signals...)
+	c.reset()
 	// Support basic tab completion
 	if compline := os.Getenv("COMP_LINE"); compline != "" {
 		return c.complete(compline)
 	}
 	// Parse the command line arguments
-	if err := c.root.parse(ctx, args); err != nil {
+	if err := c.root.parse(withInvocation(withConfig(ctx, c.config), args), args); err != nil {
 		return err
 	}
 	// Give the caller a chance to handle context cancellations and therefore
@@ -83,10 +159,18 @@ func (c *CLI) Command(name, help string) Command {
 	return c.root.Command(name, help)
 }
 
+func (c *CLI) Long(text string) Command {
+	return c.root.Long(text)
+}
+
 func (c *CLI) Hidden() Command {
 	return c.root.Hidden()
 }
 
+func (c *CLI) Deprecated(msg string) Command {
+	return c.root.Deprecated(msg)
+}
+
 func (c *CLI) Advanced() Command {
 	return c.root.Advanced()
 }
@@ -99,6 +183,10 @@ func (c *CLI) Flag(name, help string) *Flag {
 	return c.root.Flag(name, help)
 }
 
+func (c *CLI) FlagGroups(names ...string) Command {
+	return c.root.FlagGroups(names...)
+}
+
 func (c *CLI) Arg(name, help string) *Arg {
 	return c.root.Arg(name, help)
 }
@@ -107,6 +195,10 @@ func (c *CLI) Args(name, help string) *Args {
 	return c.root.Args(name, help)
 }
 
+func (c *CLI) Example(cmdline, help string) Command {
+	return c.root.Example(cmdline, help)
+}
+
 func (c *CLI) Run(runner func(ctx context.Context) error) {
 	c.root.Run(runner)
 }
@@ -115,6 +207,14 @@ func (c *CLI) Use(middlewares ...Middleware) Command {
 	return c.root.Use(middlewares...)
 }
 
+func (c *CLI) UseLocal(middlewares ...Middleware) Command {
+	return c.root.UseLocal(middlewares...)
+}
+
+func (c *CLI) Isolated() Command {
+	return c.root.Isolated()
+}
+
 func (c *CLI) Find(subcommand ...string) (Command, error) {
 	return c.find(subcommand...)
 }
@@ -128,19 +228,44 @@ func (c *CLI) find(subcommand ...string) (*command, error) {
 }
 
 func (c *CLI) complete(compline string) error {
-	fields := strings.Fields(compline)
-	cmd, err := c.find(fields[1:]...)
+	fields := strings.Fields(compline)[1:]
+	// Complete "help <command>" as if it were "<command>"
+	if c.help && len(fields) > 0 && fields[0] == "help" {
+		fields = fields[1:]
+	}
+	// Complete the flags when completing a word that starts with "-"
+	completeFlags := len(fields) > 0 && strings.HasPrefix(fields[len(fields)-1], "-") && !strings.HasSuffix(compline, " ")
+	if completeFlags {
+		fields = fields[:len(fields)-1]
+	}
+	cmd, err := c.find(fields...)
 	if err != nil {
 		// If the command wasn't found, don't print anything
 		return nil
 	}
-	for _, cmd := range cmd.commands {
+	if completeFlags {
+		// Deprecated flags and names are still completed
+		for _, flag := range cmd.flags {
+			if flag.hidden {
+				continue
+			}
+			c.config.stdout.Write([]byte("--" + flag.name + "\n"))
+			for _, alias := range flag.aliases {
+				c.config.stdout.Write([]byte("--" + alias + "\n"))
+			}
+			for _, name := range flag.oldNames {
+				c.config.stdout.Write([]byte("--" + name + "\n"))
+			}
+		}
+		return nil
+	}
+	for _, cmd := range sortedCommands(cmd) {
 		if cmd.hidden {
 			continue
 		}
-		c.config.writer.Write([]byte(cmd.name + "\n"))
-		if cmd.alias != "" {
-			c.config.writer.Write([]byte(cmd.alias + "\n"))
+		c.config.stdout.Write([]byte(cmd.name + "\n"))
+		for _, alias := range cmd.aliases {
+			c.config.stdout.Write([]byte(alias + "\n"))
 		}
 	}
 	return nil
@@ -159,25 +284,18 @@ func trap(parent context.Context, signals ...os.Signal) context.Context {
 	return ctx
 }
 
-func lookupEnv(key *string) (string, bool) {
-	if key == nil {
-		return "", false
-	}
-	return os.LookupEnv(*key)
-}
-
 type missingInputError struct {
 	Key string
-	Env *string
+	Env *env
 }
 
 func (m *missingInputError) Error() string {
 	s := new(strings.Builder)
 	s.WriteString("missing ")
 	s.WriteString(m.Key)
-	if m.Env != nil {
+	if hasEnv(m.Env) {
 		s.WriteString(" or ")
-		s.WriteString("$" + *m.Env)
+		s.WriteString(m.Env.String())
 		s.WriteString(" environment variable")
 	}
 	return s.String()
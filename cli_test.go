@@ -6,12 +6,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/livebud/cli"
@@ -29,7 +33,7 @@ func replaceEscapeCodes(str string) string {
 	r := strings.NewReplacer(
 		"\033[0m", `{reset}`,
 		"\033[1m", `{bold}`,
-		"\033[37m", `{dim}`,
+		"\033[2m", `{dim}`,
 		"\033[4m", `{underline}`,
 		"\033[36m", `{teal}`,
 		"\033[34m", `{blue}`,
@@ -62,7 +66,7 @@ func heroku(w io.Writer) *cli.CLI {
 		Remote *string
 	}
 	var g global
-	cli := cli.New("heroku", `CLI to interact with Heroku`).Writer(w)
+	cli := cli.New("heroku", `CLI to interact with Heroku`).Writer(w).Color(cli.ColorAlways)
 	cli.Flag("app", "app to run command against").Short('a').String(&g.App)
 	cli.Flag("remote", "git remote of app to use").Short('r').Optional().String(&g.Remote)
 
@@ -190,8 +194,8 @@ func TestHerokuHelp(t *testing.T) {
     CLI to interact with Heroku
 
   {bold}Flags:{reset}
-    -a, --app     {dim}app to run command against{reset}
-    -r, --remote  {dim}git remote of app to use (optional){reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
 
   {bold}Commands:{reset}
     addons  {dim}lists your add-ons and attachments{reset}
@@ -215,9 +219,9 @@ func TestHerokuHelpPs(t *testing.T) {
     list dynos for an app
 
   {bold}Flags:{reset}
-    -a, --app     {dim}app to run command against{reset}
-    -r, --remote  {dim}git remote of app to use (optional){reset}
-    --json        {dim}output in json format (default:"false"){reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
+    --json                 {dim}output in json format (default:"false"){reset}
 
   {bold}Commands:{reset}
     autoscale  {dim}enable autoscaling for an app{reset}
@@ -241,9 +245,9 @@ func TestHerokuHelpPsAutoscale(t *testing.T) {
     enable autoscaling for an app
 
   {bold}Flags:{reset}
-    -a, --app     {dim}app to run command against{reset}
-    -r, --remote  {dim}git remote of app to use (optional){reset}
-    --json        {dim}output in json format (default:"false"){reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
+    --json                 {dim}output in json format (default:"false"){reset}
 
   {bold}Commands:{reset}
     disable  {dim}disable autoscaling for an app{reset}
@@ -267,13 +271,13 @@ func TestHerokuHelpPsAutoscaleEnable(t *testing.T) {
     enable autoscaling for an app
 
   {bold}Flags:{reset}
-    -a, --app        {dim}app to run command against{reset}
-    -r, --remote     {dim}git remote of app to use (optional){reset}
-    --json           {dim}output in json format (default:"false"){reset}
-    --max            {dim}maximum number of dynos{reset}
-    --min            {dim}minimum number of dynos{reset}
-    --notifications  {dim}comma-separated list of notifications to enable{reset}
-    --p95            {dim}95th percentile response time threshold{reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
+    --json                 {dim}output in json format (default:"false"){reset}
+    --max <int>            {dim}maximum number of dynos{reset}
+    --min <int>            {dim}minimum number of dynos{reset}
+    --notifications        {dim}comma-separated list of notifications to enable{reset}
+    --p95 <int>            {dim}95th percentile response time threshold{reset}
 
 `)
 }
@@ -314,7 +318,7 @@ func TestHerokuInvalidFlagArgFlagOrderTwo(t *testing.T) {
 func TestHelpArg(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cmd := cli.New("cp", "copy files").Writer(actual)
+	cmd := cli.New("cp", "copy files").Color(cli.ColorAlways).Writer(actual)
 	cmd.Arg("src", "source").String(nil)
 	cmd.Arg("dst", "destination").String(nil).Default(".")
 	cmd.Run(func(ctx context.Context) error { return nil })
@@ -338,7 +342,7 @@ func TestHelpArg(t *testing.T) {
 func TestHelpArgs(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cmd := cli.New("cp", "copy files").Writer(actual)
+	cmd := cli.New("cp", "copy files").Color(cli.ColorAlways).Writer(actual)
 	// strings := []string{}
 	cmd.Args("files", "all files").Strings(nil)
 	cmd.Run(func(ctx context.Context) error { return nil })
@@ -427,7 +431,7 @@ func TestFlagStringEmptyDefault(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
 	called := 0
-	cli := cli.New("cli", "desc").Writer(actual)
+	cli := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
 	cli.Run(func(ctx context.Context) error {
 		called++
 		return nil
@@ -445,7 +449,7 @@ func TestFlagStringEmptyDefault(t *testing.T) {
     desc
 
   {bold}Flags:{reset}
-    --flag  {dim}cli flag (default:""){reset}
+    --flag <string>  {dim}cli flag (default:""){reset}
 
 `)
 }
@@ -1039,7 +1043,7 @@ func TestFlagStringsEmptyDefaultHelp(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
 	called := 0
-	cli := cli.New("cli", "desc").Writer(actual)
+	cli := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
 	cli.Run(func(ctx context.Context) error {
 		called++
 		return nil
@@ -1057,7 +1061,7 @@ func TestFlagStringsEmptyDefaultHelp(t *testing.T) {
     desc
 
   {bold}Flags:{reset}
-    --flag  {dim}cli flag (default:"[]"){reset}
+    --flag <string>  {dim}cli flag (default:"[]"){reset}
 
 `)
 }
@@ -1230,7 +1234,7 @@ func TestFlagStringMapEmptyDefaultHelp(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
 	called := 0
-	cli := cli.New("cli", "desc").Writer(actual)
+	cli := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
 	cli.Run(func(ctx context.Context) error {
 		called++
 		return nil
@@ -1248,7 +1252,7 @@ func TestFlagStringMapEmptyDefaultHelp(t *testing.T) {
     desc
 
   {bold}Flags:{reset}
-    --flag  {dim}cli flag (default:"{}"){reset}
+    --flag <key:value>  {dim}cli flag (default:"{}"){reset}
 
 `)
 }
@@ -1345,7 +1349,7 @@ func TestSub(t *testing.T) {
 func TestSubHelp(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("bud", "bud CLI").Writer(actual)
+	cli := cli.New("bud", "bud CLI").Color(cli.ColorAlways).Writer(actual)
 	cli.Flag("log", "specify the logger").Bool(nil)
 	cli.Command("run", "run your application")
 	cli.Command("build", "build your application")
@@ -1372,7 +1376,7 @@ func TestSubHelp(t *testing.T) {
 func TestEmptyUsage(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("bud", "bud CLI").Writer(actual)
+	cli := cli.New("bud", "bud CLI").Color(cli.ColorAlways).Writer(actual)
 	cli.Flag("log", "").Bool(nil)
 	cli.Command("run", "")
 	ctx := context.Background()
@@ -1397,7 +1401,7 @@ func TestEmptyUsage(t *testing.T) {
 func TestSubHelpShort(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("bud", "bud CLI").Writer(actual)
+	cli := cli.New("bud", "bud CLI").Color(cli.ColorAlways).Writer(actual)
 	cli.Flag("log", "specify the logger").Short('L').Bool(nil).Default(false)
 	cli.Flag("debug", "set the debugger").Bool(nil).Default(true)
 	var trace []string
@@ -1569,7 +1573,107 @@ func TestInterrupt(t *testing.T) {
 	testchild.Run(t, parent, child)
 }
 
-// TODO: example support
+func TestExampleUsage(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("heroku", "heroku cli").Color(cli.ColorAlways).Writer(actual)
+	scale := app.Command("ps", "list dynos").Command("scale", "scale dynos")
+	scale.Arg("value", "dyno quantity").String(nil)
+	scale.Example("web=1", "scale web dynos to one")
+	scale.Example("worker=2", "")
+	scale.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "ps", "scale", "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} heroku ps scale {dim}<value>{reset}
+
+  {bold}Description:{reset}
+    scale dynos
+
+  {bold}Args:{reset}
+    <value>  {dim}dyno quantity{reset}
+
+  {bold}Examples:{reset}
+    {dim}# scale web dynos to one{reset}
+    {dim}${reset} heroku ps scale web=1
+
+    {dim}${reset} heroku ps scale worker=2
+
+`)
+}
+
+func TestVerifyExamples(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual)
+	app.Example("--app=foo ps", "list dynos")
+	ps, err := app.Find("ps")
+	is.NoErr(err)
+	ps.Example("--app foo --json", "list dynos as json")
+	scale, err := app.Find("ps", "scale")
+	is.NoErr(err)
+	scale.Example("web=1 --app=foo", "scale web dynos")
+	ctx := context.Background()
+	is.NoErr(app.VerifyExamples(ctx))
+	// Verifying examples doesn't run any commands
+	is.Equal(actual.String(), "")
+}
+
+func TestVerifyExamplesStale(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual)
+	scale, err := app.Find("ps", "scale")
+	is.NoErr(err)
+	scale.Example("web=1 --app=foo --size=2", "scale web dynos")
+	ctx := context.Background()
+	err = app.VerifyExamples(ctx)
+	is.True(errors.Is(err, cli.ErrInvalidInput))
+	is.Equal(err.Error(), `cli: invalid input example "heroku ps scale web=1 --app=foo --size=2": flag provided but not defined: -size`)
+}
+
+func TestVerifyExamplesStaleState(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	var name string
+	var tags []string
+	app.Flag("name", "name").String(&name)
+	app.Flag("tag", "tags").Optional().Strings(&tags)
+	app.Example("--name=foo --tag=a", "with a name")
+	app.Example("", "without a name")
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	// The first example doesn't set the name for the second example
+	err := app.VerifyExamples(ctx)
+	is.True(errors.Is(err, cli.ErrInvalidInput))
+	is.Equal(err.Error(), `cli: invalid input example "cli": missing --name`)
+	// Values don't leak between parses either
+	err = app.Parse(ctx, "--name=bar", "--tag=b")
+	is.NoErr(err)
+	is.Equal(name, "bar")
+	is.Equal(tags, []string{"b"})
+	err = app.Parse(ctx, "--name=baz")
+	is.NoErr(err)
+	is.Equal(tags, []string{})
+}
+
+func TestWalkExamples(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual)
+	app.Command("build", "build command").Example("--minify", "build for production")
+	examples := []string{}
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		for _, example := range cmd.Examples() {
+			examples = append(examples, example.Command()+": "+example.Help())
+		}
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(examples, []string{"cli build --minify: build for production"})
+}
 
 func TestArgsStrings(t *testing.T) {
 	is := is.New(t)
@@ -1717,7 +1821,7 @@ func TestUsageError(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
 	called := 0
-	cmd := cli.New("cli", "cli command").Writer(actual)
+	cmd := cli.New("cli", "cli command").Color(cli.ColorAlways).Writer(actual)
 	cmd.Run(func(ctx context.Context) error {
 		called++
 		return cli.Usage()
@@ -1788,7 +1892,7 @@ func TestManualHelp(t *testing.T) {
 func TestManualHelpUsage(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cmd := cli.New("cli", "cli command").Writer(actual)
+	cmd := cli.New("cli", "cli command").Color(cli.ColorAlways).Writer(actual)
 	var help bool
 	var dir string
 	cmd.Flag("help", "help menu").Short('h').Bool(&help).Default(false)
@@ -1812,8 +1916,8 @@ func TestManualHelpUsage(t *testing.T) {
     cli command
 
   {bold}Flags:{reset}
-    -C, --chdir  {dim}change directory{reset}
-    -h, --help   {dim}help menu (default:"false"){reset}
+    -C, --chdir <string>  {dim}change directory{reset}
+    -h, --help            {dim}help menu (default:"false"){reset}
 
 `)
 }
@@ -3154,7 +3258,7 @@ func TestUsageNestCommandArg(t *testing.T) {
 	actual := new(bytes.Buffer)
 	var path string
 	called := 0
-	cli := cli.New("bud", "bud cli").Writer(actual)
+	cli := cli.New("bud", "bud cli").Color(cli.ColorAlways).Writer(actual)
 	{
 		cli := cli.Command("fs", "filesystem tools")
 		{
@@ -3185,7 +3289,7 @@ func TestUsageNestCommandArg(t *testing.T) {
 func TestHiddenCommand(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("cli", "cli command").Writer(actual)
+	cli := cli.New("cli", "cli command").Color(cli.ColorAlways).Writer(actual)
 	cli.Command("foo", "foo command").Hidden()
 	cli.Command("bar", "bar command")
 	ctx := context.Background()
@@ -3223,7 +3327,7 @@ func TestHiddenCommandRunnable(t *testing.T) {
 func TestAdvancedCommand(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("cli", "cli command").Writer(actual)
+	cli := cli.New("cli", "cli command").Color(cli.ColorAlways).Writer(actual)
 	cli.Command("foo", "foo command")
 	cli.Command("bar", "bar command").Advanced()
 	ctx := context.Background()
@@ -3413,7 +3517,7 @@ func TestCommandAlias(t *testing.T) {
 func TestCommandAliasUsage(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("bud", "bud cli").Writer(actual)
+	cli := cli.New("bud", "bud cli").Color(cli.ColorAlways).Writer(actual)
 	env := cli.Command("env", "environment tools")
 	env.Command("list", "list environment variables").Alias("ls")
 	ctx := context.Background()
@@ -3432,6 +3536,66 @@ func TestCommandAliasUsage(t *testing.T) {
 `)
 }
 
+func TestCommandAliases(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	called := 0
+	app := cli.New("bud", "bud cli").Color(cli.ColorAlways).Writer(actual).HelpCommand()
+	env := app.Command("env", "environment tools")
+	list := env.Command("list", "list environment variables").Alias("ls").Alias("l")
+	list.Run(func(ctx context.Context) error {
+		called++
+		return nil
+	})
+	env.Command("get", "get an environment variable").Alias("g")
+	ctx := context.Background()
+	err := app.Parse(ctx, "env", "l")
+	is.NoErr(err)
+	is.Equal(1, called)
+	err = app.Parse(ctx, "env", "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} bud env {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    environment tools
+
+  {bold}Commands:{reset}
+    get   {dim}get an environment variable (alias: g){reset}
+    list  {dim}list environment variables (aliases: ls, l){reset}
+
+`)
+	// Aliases are suggested
+	err = app.Parse(ctx, "help", "env", "lss")
+	is.True(errors.Is(err, cli.ErrCommandNotFound))
+	is.Equal(err.Error(), `cli: command not found: env lss. Did you mean "l", "list" or "ls"?`)
+	// Aliases are completed
+	actual.Reset()
+	t.Setenv("COMP_LINE", "bud env ")
+	err = app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "get\ng\nlist\nls\nl\n")
+}
+
+func TestCommandAliasConflict(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("bud", "bud cli")
+	app.Command("list", "list things").Alias("ls")
+	app.Command("lsof", "list open files")
+	conflict := func(fn func()) (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		fn()
+		return ""
+	}
+	// Conflicts with a sibling
+	is.Equal(conflict(func() { app.Command("list", "").Alias("lsof") }), `cli: cannot alias "bud list" to "lsof" because it already exists`)
+	// Conflicts with another alias
+	is.Equal(conflict(func() { app.Command("lsof", "").Alias("ls") }), `cli: cannot alias "bud lsof" to "ls" because it already exists`)
+	// Commands can't reuse an alias
+	is.Equal(conflict(func() { app.Command("ls", "") }), `cli: cannot create "bud ls" because it's already an alias of "bud list"`)
+}
+
 func TestFlagEnumInvalid(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
@@ -4017,7 +4181,7 @@ func TestEnvHelp(t *testing.T) {
 	is := is.New(t)
 	ctx := context.Background()
 	actual := new(bytes.Buffer)
-	cli := cli.New("cli", "cli command").Writer(actual)
+	cli := cli.New("cli", "cli command").Color(cli.ColorAlways).Writer(actual)
 	verbose := false
 	log := ""
 	n := 0
@@ -4041,12 +4205,12 @@ func TestEnvHelp(t *testing.T) {
     cli command
 
   {bold}Flags:{reset}
-    --arr      {dim}arr (or $ARR){reset}
-    --dir      {dim}dir (or $DIR){reset}
-    --log      {dim}log level (or $LOG, default:"info"){reset}
-    --mp       {dim}mp (or $MP){reset}
-    --n        {dim}n (or $N){reset}
-    --verbose  {dim}verbose (or $VERBOSE){reset}
+    --arr <string>                 {dim}arr (or $ARR){reset}
+    --dir <string>                 {dim}dir (or $DIR){reset}
+    --log <debug|info|warn|error>  {dim}log level (or $LOG, default:"info"){reset}
+    --mp <key:value>               {dim}mp (or $MP){reset}
+    --n <int>                      {dim}n (or $N){reset}
+    --verbose                      {dim}verbose (or $VERBOSE){reset}
 
 `)
 }
@@ -4140,10 +4304,13 @@ func TestMissingSetter(t *testing.T) {
 func TestMissingRunTriggersHelp(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
-	cli := cli.New("cli", "desc").Writer(actual)
+	stdout := new(bytes.Buffer)
+	cli := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(actual)
 	ctx := context.Background()
 	err := cli.Parse(ctx)
 	is.NoErr(err)
+	// The usage wasn't asked for, so it's printed to stderr
+	is.Equal(stdout.String(), "")
 	isEqual(t, actual.String(), `
   {bold}Usage:{reset}
     {dim}${reset} cli
@@ -4187,7 +4354,7 @@ func TestMiddlewareOrder(t *testing.T) {
 	ctx := context.Background()
 	err := cli.Parse(ctx, "sub")
 	is.NoErr(err)
-	is.Equal(trace, []string{"sub-mw", "sub-mw-2", "sub-run"})
+	is.Equal(trace, []string{"root-mw", "sub-mw", "sub-mw-2", "sub-run"})
 }
 
 func TestMiddlewareError(t *testing.T) {
@@ -4237,6 +4404,51 @@ func TestMiddlewareContext(t *testing.T) {
 	is.Equal(actual.String(), "value")
 }
 
+func TestMiddlewareInheritance(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "middleware inheritance").Writer(actual)
+	trace := []string{}
+	mw := func(name string) cli.Middleware {
+		return func(next func(ctx context.Context) error) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				trace = append(trace, name)
+				return next(ctx)
+			}
+		}
+	}
+	run := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			trace = append(trace, name)
+			return nil
+		}
+	}
+	app.Use(mw("root-mw"))
+	app.UseLocal(mw("root-local"))
+	app.Run(run("root-run"))
+	deploy := app.Command("deploy", "deploy the app")
+	deploy.Use(mw("deploy-mw"))
+	deploy.Run(run("deploy-run"))
+	preview := deploy.Command("preview", "deploy a preview")
+	preview.Use(mw("preview-mw"))
+	preview.Run(run("preview-run"))
+	login := app.Command("login", "log in").Isolated()
+	login.Use(mw("login-mw"))
+	login.Run(run("login-run"))
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(trace, []string{"root-mw", "root-local", "root-run"})
+	trace = trace[:0]
+	err = app.Parse(ctx, "deploy", "preview")
+	is.NoErr(err)
+	is.Equal(trace, []string{"root-mw", "deploy-mw", "preview-mw", "preview-run"})
+	trace = trace[:0]
+	err = app.Parse(ctx, "login")
+	is.NoErr(err)
+	is.Equal(trace, []string{"login-mw", "login-run"})
+}
+
 func TestArgsInt64s(t *testing.T) {
 	is := is.New(t)
 	actual := new(bytes.Buffer)
@@ -5142,3 +5354,2001 @@ func TestArgDash(t *testing.T) {
 	is.Equal(1, called)
 	is.Equal(s, "-")
 }
+
+func TestWalk(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual)
+	paths := []string{}
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		paths = append(paths, strings.Join(path, " "))
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(paths, []string{
+		"",
+		"addons",
+		"addons attach",
+		"addons create",
+		"addons destroy",
+		"addons info",
+		"ps",
+		"ps autoscale",
+		"ps autoscale disable",
+		"ps autoscale enable",
+		"ps scale",
+	})
+}
+
+func TestWalkInfo(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual)
+	var port int
+	var tags []string
+	app.Flag("port", "port to serve").Short('p').Env("PORT").Int(&port).Default(3000)
+	list := app.Command("list", "list files")
+	list.Alias("ls")
+	list.Arg("dir", "directory").Optional().String(nil)
+	list.Args("tags", "tags").Strings(&tags)
+	list.Run(func(ctx context.Context) error { return nil })
+	app.Command("debug", "debug command").Hidden()
+	infos := map[string]cli.CommandInfo{}
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		infos[strings.Join(path, " ")] = cmd
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(len(infos), 3)
+	root := infos[""]
+	is.Equal(root.Name(), "cli")
+	is.Equal(root.Help(), "desc")
+	is.Equal(root.Runnable(), false)
+	is.Equal(len(root.Commands()), 2)
+	is.Equal(len(root.Flags()), 1)
+	flag := root.Flags()[0]
+	is.Equal(flag.Name(), "port")
+	is.Equal(flag.Short(), "p")
+	env, ok := flag.Env()
+	is.True(ok)
+	is.Equal(env, "PORT")
+	def, ok := flag.Default()
+	is.True(ok)
+	is.Equal(def, "3000")
+	is.Equal(flag.Optional(), false)
+	list2 := infos["list"]
+	is.Equal(list2.Full(), "cli list")
+	is.Equal(list2.Aliases(), []string{"ls"})
+	is.Equal(list2.Runnable(), true)
+	is.Equal(len(list2.Flags()), 1)
+	is.Equal(len(list2.Args()), 1)
+	is.Equal(list2.Args()[0].Name(), "dir")
+	is.Equal(list2.Args()[0].Optional(), true)
+	is.Equal(list2.Args()[0].Variadic(), false)
+	is.Equal(list2.RestArgs().Name(), "tags")
+	is.Equal(list2.RestArgs().Variadic(), true)
+	is.Equal(infos["debug"].IsHidden(), true)
+	is.Equal(root.RestArgs(), nil)
+}
+
+func TestWalkSkipCommand(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual)
+	paths := []string{}
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		paths = append(paths, strings.Join(path, " "))
+		if cmd.Name() == "addons" || cmd.Name() == "autoscale" {
+			return cli.SkipCommand
+		}
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(paths, []string{"", "addons", "ps", "ps autoscale", "ps scale"})
+}
+
+func TestWalkError(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual)
+	count := 0
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		count++
+		if cmd.Name() == "create" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	is.True(err != nil)
+	is.Equal(err.Error(), "stop")
+	is.Equal(count, 4)
+}
+
+func TestHelpCommand(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual).HelpCommand()
+	ctx := context.Background()
+	err := app.Parse(ctx, "help", "ps", "autoscale")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} heroku ps autoscale {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    enable autoscaling for an app
+
+  {bold}Flags:{reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
+    --json                 {dim}output in json format (default:"false"){reset}
+
+  {bold}Commands:{reset}
+    disable  {dim}disable autoscaling for an app{reset}
+    enable   {dim}enable autoscaling for an app{reset}
+
+`)
+}
+
+func TestHelpCommandRoot(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual).HelpCommand()
+	ctx := context.Background()
+	err := app.Parse(ctx, "help")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} heroku {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    CLI to interact with Heroku
+
+  {bold}Flags:{reset}
+    -a, --app <string>     {dim}app to run command against{reset}
+    -r, --remote <string>  {dim}git remote of app to use (optional){reset}
+
+  {bold}Commands:{reset}
+    addons  {dim}lists your add-ons and attachments{reset}
+    help    {dim}show help for a command{reset}
+    ps      {dim}list dynos for an app{reset}
+
+`)
+}
+
+func TestHelpCommandNotFound(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual).HelpCommand()
+	ctx := context.Background()
+	err := app.Parse(ctx, "help", "ps", "autoscal")
+	is.True(errors.Is(err, cli.ErrCommandNotFound))
+	is.Equal(err.Error(), `cli: command not found: ps autoscal. Did you mean "autoscale"?`)
+	err = app.Parse(ctx, "help", "adons")
+	is.True(errors.Is(err, cli.ErrCommandNotFound))
+	is.Equal(err.Error(), `cli: command not found: adons. Did you mean "addons"?`)
+	err = app.Parse(ctx, "help", "zzzzzzzz")
+	is.True(errors.Is(err, cli.ErrCommandNotFound))
+	is.Equal(err.Error(), `cli: command not found: zzzzzzzz`)
+	is.Equal(actual.String(), "")
+}
+
+func TestHelpCommandComplete(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := heroku(actual).HelpCommand()
+	t.Setenv("COMP_LINE", "heroku help ps ")
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "autoscale\nscale\n")
+}
+
+func TestUsageWrap(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Width(50)
+	var dir, log string
+	app.Flag("dir", "the directory to run the command in, relative to the current working directory").Short('C').String(&dir).Default(".")
+	app.Flag("log", "log level").String(&log).Default("info")
+	app.Command("deploy", "deploy the application to every region that is configured in the project")
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    -C, --dir <string>  {dim}the directory to run the{reset}
+                        {dim}command in, relative to{reset}
+                        {dim}the current working{reset}
+                        {dim}directory (default:"."){reset}
+    --log <string>      {dim}log level (default:"info"){reset}
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the application to every region{reset}
+            {dim}that is configured in the project{reset}
+
+`)
+}
+
+func TestUsageWrapColumns(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("COLUMNS", "40")
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	app.Arg("path", "path to the file that should be copied").String(nil)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}<path>{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Args:{reset}
+    <path>  {dim}path to the file that should{reset}
+            {dim}be copied{reset}
+
+`)
+}
+
+func TestUsageWideCharacters(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Width(80)
+	app.Command("日本", "japan")
+	app.Command("ab", "letters")
+	app.Command("🚀", "rocket")
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Commands:{reset}
+    ab    {dim}letters{reset}
+    日本  {dim}japan{reset}
+    🚀    {dim}rocket{reset}
+
+`)
+}
+
+func TestCommandLong(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Width(60)
+	deploy := app.Command("deploy", "deploy the application")
+	deploy.Long(`
+		Deploy builds the application and uploads it to every region
+		that's configured for the project.
+
+		Deploys are atomic, so if any region fails, the previous
+		release keeps serving traffic.
+
+		    cli deploy --region us-east
+	`)
+	deploy.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the application{reset}
+
+`)
+	actual.Reset()
+	err = app.Parse(ctx, "deploy", "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli deploy
+
+  {bold}Description:{reset}
+    Deploy builds the application and uploads it to every
+    region that's configured for the project.
+
+    Deploys are atomic, so if any region fails, the previous
+    release keeps serving traffic.
+
+        cli deploy --region us-east
+
+`)
+}
+
+func TestFlagLongVerbose(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Width(60)
+	var format string
+	app.Flag("format", "output format").Long(`
+		The format controls how results are printed. Use json when
+		piping the output into another program.
+	`).String(&format).Default("text")
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --format <string>  {dim}output format (default:"text"){reset}
+
+`)
+	actual.Reset()
+	err = app.Parse(ctx, "--help")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --format <string>  {dim}output format (default:"text"){reset}
+
+                       {dim}The format controls how results are{reset}
+                       {dim}printed. Use json when piping the{reset}
+                       {dim}output into another program.{reset}
+
+`)
+	// A flag value of --help doesn't make -h verbose
+	actual.Reset()
+	err = app.Parse(ctx, "--format", "--help", "-h")
+	is.NoErr(err)
+	is.True(!strings.Contains(actual.String(), "The format controls"))
+	// Neither do arguments after the help flag
+	actual.Reset()
+	err = app.Parse(ctx, "-h", "--help")
+	is.NoErr(err)
+	is.True(!strings.Contains(actual.String(), "The format controls"))
+}
+
+func TestFlagGroups(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var host, format string
+	var port int
+	var verbose, color bool
+	app.Flag("verbose", "verbose logging").Short('v').Bool(&verbose).Default(false)
+	app.Flag("port", "port to listen on").Group("Networking").Int(&port).Default(3000)
+	app.Flag("format", "output format").Group("Output").String(&format).Default("text")
+	app.Flag("host", "host to listen on").Group("Networking").String(&host).Default("localhost")
+	app.Flag("color", "colorize output").Group("Output").Bool(&color).Default(true)
+	app.FlagGroups("Output")
+	app.Command("serve", "serve the app").Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    -v, --verbose  {dim}verbose logging (default:"false"){reset}
+
+  {bold}Output Flags:{reset}
+    --color            {dim}colorize output (default:"true"){reset}
+    --format <string>  {dim}output format (default:"text"){reset}
+
+  {bold}Networking Flags:{reset}
+    --host <string>  {dim}host to listen on (default:"localhost"){reset}
+    --port <int>     {dim}port to listen on (default:"3000"){reset}
+
+  {bold}Commands:{reset}
+    serve  {dim}serve the app{reset}
+
+`)
+	// Subcommands inherit the groups and their order
+	actual.Reset()
+	err = app.Parse(ctx, "serve", "--port", "8080", "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli serve {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    serve the app
+
+  {bold}Flags:{reset}
+    -v, --verbose  {dim}verbose logging (default:"false"){reset}
+
+  {bold}Output Flags:{reset}
+    --color            {dim}colorize output (default:"true"){reset}
+    --format <string>  {dim}output format (default:"text"){reset}
+
+  {bold}Networking Flags:{reset}
+    --host <string>  {dim}host to listen on (default:"localhost"){reset}
+    --port <int>     {dim}port to listen on (default:"3000"){reset}
+
+`)
+}
+
+func TestFlagGroupsCustomUsage(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	tpl := template.Must(template.New("usage").Parse("{{ $.Flags.Usage }}\n---\n{{ $.UngroupedFlags.Usage }}\n"))
+	app := cli.New("cli", "desc").Writer(actual).Usage(tpl).Color(cli.ColorNever)
+	var host string
+	var verbose, debug bool
+	app.Flag("verbose", "verbose logging").Bool(&verbose).Default(false)
+	app.Flag("host", "host to listen on").Group("Networking").String(&host).Default("localhost")
+	app.Flag("debug", "debug mode").Advanced().Bool(&debug).Default(false)
+	err := app.Parse(context.Background(), "-h")
+	is.NoErr(err)
+	// Custom templates that range over .Flags still see the grouped flags
+	is.Equal(actual.String(), `--debug          debug mode (default:"false")
+    --host <string>  host to listen on (default:"localhost")
+    --verbose        verbose logging (default:"false")
+---
+--verbose  verbose logging (default:"false")
+`)
+}
+
+func TestFlagGroupsInfo(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual)
+	var port int
+	app.Flag("port", "port to listen on").Group("Networking").Int(&port).Default(3000)
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		is.Equal(cmd.FlagGroups(), []string{"Networking"})
+		is.Equal(cmd.Flags()[0].Group(), "Networking")
+		return nil
+	})
+	is.NoErr(err)
+}
+
+func TestFlagPlaceholders(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var timeout time.Duration
+	var format, config string
+	var endpoint url.URL
+	var tags []string
+	app.Flag("timeout", "request timeout").Duration(&timeout).Default(time.Second)
+	app.Flag("format", "output format").Enum(&format, "json", "yaml", "table").Default("table")
+	app.Flag("config", "config file").Placeholder("FILE").String(&config).Default("app.json")
+	app.Flag("endpoint", "api endpoint").Url(&endpoint)
+	app.Flag("tag", "tags to apply").Optional().Enums(&tags, "a", "b")
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --config FILE               {dim}config file (default:"app.json"){reset}
+    --endpoint <url>            {dim}api endpoint{reset}
+    --format <json|yaml|table>  {dim}output format (default:"table"){reset}
+    --tag <a|b>                 {dim}tags to apply (optional){reset}
+    --timeout <duration>        {dim}request timeout (default:"1s"){reset}
+
+`)
+	err = app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		for _, flag := range cmd.Flags() {
+			if flag.Name() == "format" {
+				is.Equal(flag.Placeholder(), "<json|yaml|table>")
+			}
+		}
+		return nil
+	})
+	is.NoErr(err)
+}
+
+func TestColorNever(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual).Color(cli.ColorNever)
+	app.Command("build", "build the app")
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	is.Equal(actual.String(), `
+  Usage:
+    $ cli [command]
+
+  Description:
+    desc
+
+  Commands:
+    build  build the app
+
+`)
+}
+
+func TestColorAutoNotTerminal(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("CLICOLOR_FORCE", "")
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual)
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	is.Equal(actual.String(), `
+  Usage:
+    $ cli
+
+  Description:
+    desc
+
+`)
+}
+
+func TestColorAutoEnv(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	// CLICOLOR_FORCE colors even when not writing to a terminal
+	actual := new(bytes.Buffer)
+	t.Setenv("CLICOLOR_FORCE", "1")
+	is.NoErr(cli.New("cli", "desc").Writer(actual).Parse(ctx, "-h"))
+	is.True(strings.Contains(actual.String(), "\033[1m"))
+	// NO_COLOR takes precedence over CLICOLOR_FORCE
+	actual.Reset()
+	t.Setenv("NO_COLOR", "1")
+	is.NoErr(cli.New("cli", "desc").Writer(actual).Parse(ctx, "-h"))
+	is.True(!strings.Contains(actual.String(), "\033["))
+	// Explicitly enabling color takes precedence over the environment
+	actual.Reset()
+	is.NoErr(cli.New("cli", "desc").Writer(actual).Color(cli.ColorAlways).Parse(ctx, "-h"))
+	is.True(strings.Contains(actual.String(), "\033[1m"))
+	// TERM=dumb disables colors
+	actual.Reset()
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("TERM", "dumb")
+	is.NoErr(cli.New("cli", "desc").Writer(actual).Parse(ctx, "-h"))
+	is.True(!strings.Contains(actual.String(), "\033["))
+}
+
+func TestColorFlag(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual).Color(cli.ColorAlways)
+	app.Command("build", "build the app")
+	app.ColorFlag()
+	app.Command("test", "test the app")
+	ctx := context.Background()
+	err := app.Parse(ctx, "build", "--color=never", "-h")
+	is.NoErr(err)
+	is.Equal(actual.String(), `
+  Usage:
+    $ cli build [flags]
+
+  Description:
+    build the app
+
+  Flags:
+    --color <auto|always|never>  when to color the output (optional)
+
+`)
+	actual.Reset()
+	err = app.Parse(ctx, "test", "--color", "never", "-h")
+	is.NoErr(err)
+	is.True(!strings.Contains(actual.String(), "\033["))
+	// The next parse without --color goes back to the configured mode
+	actual.Reset()
+	err = app.Parse(ctx, "test", "-h")
+	is.NoErr(err)
+	is.True(strings.Contains(actual.String(), "\033["))
+}
+
+func TestColorFlagExisting(t *testing.T) {
+	is := is.New(t)
+	var color bool
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	deploy := app.Command("deploy", "deploy the app")
+	deploy.Flag("color", "use the color deployment").Bool(&color).Default(false)
+	deploy.Run(func(ctx context.Context) error { return nil })
+	app.ColorFlag()
+	ctx := context.Background()
+	// The command keeps its own --color flag
+	err := app.Parse(ctx, "deploy", "--color")
+	is.NoErr(err)
+	is.True(color)
+}
+
+func TestTheme(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Theme(cli.Theme{
+		Heading:     cli.Styles(cli.Bold, cli.Hex("#ff8800")),
+		Hint:        cli.Color256(244),
+		Command:     cli.Teal,
+		Flag:        cli.Green,
+		Description: cli.Dim,
+		Default:     cli.Yellow,
+	})
+	var log string
+	app.Flag("log", "log level").String(&log).Default("info")
+	app.Command("build", "build the app").Alias("b")
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	// Escape the non-standard codes so they're readable
+	expected := `
+  {bold}\e[38;2;255;136;0mUsage:{reset}
+    \e[38;5;244m${reset} cli \e[38;5;244m[flags]{reset} \e[38;5;244m[command]{reset}
+
+  {bold}\e[38;2;255;136;0mDescription:{reset}
+    desc
+
+  {bold}\e[38;2;255;136;0mFlags:{reset}
+    {green}--log <string>{reset}  {dim}log level{reset} {yellow}(default:"info"){reset}
+
+  {bold}\e[38;2;255;136;0mCommands:{reset}
+    {teal}build{reset}  {dim}build the app{reset} {yellow}(alias: b){reset}
+
+`
+	equal(t, expected, strings.ReplaceAll(replaceEscapeCodes(actual.String()), "\033", `\e`))
+}
+
+func TestHexInvalid(t *testing.T) {
+	is := is.New(t)
+	is.Equal(cli.Hex("ff8800"), cli.TrueColor(255, 136, 0))
+	invalid := func(hex string) (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		cli.Hex(hex)
+		return ""
+	}
+	is.Equal(invalid("#ff88"), `cli: invalid hex color "#ff88"`)
+	is.Equal(invalid("#ff880000"), `cli: invalid hex color "#ff880000"`)
+	is.Equal(invalid("orange"), `cli: invalid hex color "orange"`)
+}
+
+func TestThemeTemplate(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	tpl := template.Must(template.New("usage").Funcs(template.FuncMap{
+		"heading": func() string { return "" },
+		"reset":   func() string { return "" },
+	}).Parse(`{{heading}}{{ $.Full }}{{reset}}`))
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Usage(tpl).Theme(cli.Theme{Heading: cli.Blue})
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `{blue}cli{reset}`)
+	actual.Reset()
+	app.Color(cli.ColorNever)
+	err = app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `cli`)
+}
+
+func TestVersionFlag(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Version("1.2.3")
+	var dir string
+	app.Flag("dir", "directory").String(&dir)
+	deploy := app.Command("deploy", "deploy the app")
+	deploy.Arg("region", "region").String(nil)
+	deploy.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "--version")
+	is.NoErr(err)
+	is.Equal(actual.String(), "cli 1.2.3\n")
+	// --version is only on the root command
+	actual.Reset()
+	err = app.Parse(ctx, "deploy", "--version")
+	is.True(err != nil)
+	is.Equal(err.Error(), "flag provided but not defined: -version")
+	is.Equal(actual.String(), "")
+	actual.Reset()
+	err = app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --dir <string>  {dim}directory{reset}
+    --version       {dim}print the version (default:"false"){reset}
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the app{reset}
+
+`)
+}
+
+func TestVersionExample(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("app", "desc").Writer(actual).Version("1.2.3")
+	app.Example("--version", "print the version")
+	deployed := false
+	var n int
+	deploy := app.Command("deploy", "deploy the app")
+	deploy.Flag("n", "number of dynos").Int(&n)
+	deploy.Example("--bogus", "stale example")
+	deploy.Run(func(ctx context.Context) error {
+		deployed = true
+		return nil
+	})
+	ctx := context.Background()
+	err := app.VerifyExamples(ctx)
+	is.True(err != nil)
+	is.Equal(err.Error(), `cli: invalid input example "app deploy --bogus": flag provided but not defined: -bogus`)
+	// The version example doesn't leak into the next parse
+	err = app.Parse(ctx, "deploy", "--n=1")
+	is.NoErr(err)
+	is.True(deployed)
+	is.Equal(n, 1)
+	is.Equal(actual.String(), "")
+}
+
+func TestVersionBuildInfo(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual).Version("")
+	ctx := context.Background()
+	err := app.Parse(ctx, "--version")
+	is.NoErr(err)
+	is.True(strings.HasPrefix(actual.String(), "cli "))
+	is.True(len(actual.String()) > len("cli \n"))
+}
+
+func TestVersionCommandTemplate(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	tpl := template.Must(template.New("version").Parse(`{{ $.Name }} version {{ $.Version }} ({{ $.GoVersion }})`))
+	app := cli.New("cli", "desc").Writer(actual).Version("v0.1.0").VersionTemplate(tpl).VersionCommand()
+	ctx := context.Background()
+	err := app.Parse(ctx, "version")
+	is.NoErr(err)
+	is.Equal(actual.String(), "cli version v0.1.0 ("+runtime.Version()+")\n")
+}
+
+func TestDeprecatedFlag(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Stderr(stderr)
+	var legacy bool
+	var region string
+	app.Flag("legacy", "use the legacy builder").Deprecated("it no longer has an effect").Bool(&legacy)
+	app.Flag("region", "region of the app").Deprecated("").String(&region)
+	called := 0
+	app.Run(func(ctx context.Context) error {
+		called++
+		return nil
+	})
+	ctx := context.Background()
+	// Deprecated flags aren't required
+	err := app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(called, 1)
+	is.Equal(stderr.String(), "")
+	err = app.Parse(ctx, "--legacy", "--region", "eu")
+	is.NoErr(err)
+	is.Equal(called, 2)
+	is.Equal(legacy, true)
+	is.Equal(region, "eu")
+	isEqual(t, stderr.String(), "{red}warning:{reset} --legacy is deprecated, it no longer has an effect\n{red}warning:{reset} --region is deprecated\n")
+}
+
+func TestDeprecatedName(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Stderr(stderr)
+	var location []string
+	app.Flag("location", "location of the app").DeprecatedName("region").Strings(&location)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "--region", "eu", "--region", "us")
+	is.NoErr(err)
+	is.Equal(location, []string{"eu", "us"})
+	// Warnings are only printed once per parse
+	isEqual(t, stderr.String(), "{red}warning:{reset} --region is deprecated, use --location instead\n")
+}
+
+func TestDeprecatedNameDuplicate(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("cli", "desc").Writer(io.Discard)
+	var location, region string
+	app.Flag("location", "location of the app").DeprecatedName("region").String(&location)
+	app.Flag("region", "region of the app").String(&region)
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.True(err != nil)
+	is.Equal(err.Error(), `cli: invalid input "cli" command contains a duplicate flag "--region"`)
+}
+
+func TestDeprecatedEnv(t *testing.T) {
+	is := is.New(t)
+	stderr := new(bytes.Buffer)
+	var location, name string
+	newApp := func() *cli.CLI {
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(io.Discard).Stderr(stderr)
+		app.Flag("location", "location of the app").Env("LOCATION").DeprecatedEnv("REGION").String(&location)
+		app.Arg("name", "name of the app").Env("NAME").DeprecatedEnv("$APP_NAME").String(&name)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	t.Setenv("REGION", "eu")
+	t.Setenv("APP_NAME", "web")
+	ctx := context.Background()
+	err := newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(location, "eu")
+	is.Equal(name, "web")
+	isEqual(t, stderr.String(), "{red}warning:{reset} $APP_NAME is deprecated, use $NAME instead\n{red}warning:{reset} $REGION is deprecated, use $LOCATION instead\n")
+	// The new environment variable takes precedence
+	stderr.Reset()
+	t.Setenv("LOCATION", "us")
+	err = newApp().Parse(ctx, "api")
+	is.NoErr(err)
+	is.Equal(location, "us")
+	is.Equal(name, "api")
+	is.Equal(stderr.String(), "")
+}
+
+func TestDeprecatedEnvEnum(t *testing.T) {
+	is := is.New(t)
+	stderr := new(bytes.Buffer)
+	var format string
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(io.Discard).Stderr(stderr)
+	app.Flag("format", "output format").DeprecatedEnv("OUTPUT").Enum(&format, "json", "yaml").Env("$FORMAT")
+	app.Run(func(ctx context.Context) error { return nil })
+	t.Setenv("OUTPUT", "yaml")
+	err := app.Parse(context.Background())
+	is.NoErr(err)
+	is.Equal(format, "yaml")
+	isEqual(t, stderr.String(), "{red}warning:{reset} $OUTPUT is deprecated, use $FORMAT instead\n")
+}
+
+func TestDeprecatedExamples(t *testing.T) {
+	is := is.New(t)
+	stderr := new(bytes.Buffer)
+	var region *string
+	var location string
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(io.Discard).Stderr(stderr)
+	app.Flag("region", "region").Deprecated("use --location").Optional().String(&region)
+	app.Flag("location", "location").String(&location).Default("us")
+	app.Command("ship", "ship the app").Deprecated("").Run(func(ctx context.Context) error { return nil })
+	app.Example("--region=eu ship", "ship to europe")
+	app.Run(func(ctx context.Context) error { return nil })
+	// Verifying examples doesn't print deprecation warnings
+	err := app.VerifyExamples(context.Background())
+	is.NoErr(err)
+	is.Equal(stderr.String(), "")
+	err = app.Parse(context.Background(), "--region=eu")
+	is.NoErr(err)
+	isEqual(t, stderr.String(), "{red}warning:{reset} --region is deprecated, use --location\n")
+}
+
+func TestDeprecatedCommand(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual).Stderr(stderr)
+	called := 0
+	app.Command("deploy", "deploy the app").Run(func(ctx context.Context) error {
+		called++
+		return nil
+	})
+	ship := app.Command("ship", "ship the app").Deprecated(`use "cli deploy"`)
+	ship.Arg("target", "where to ship").Optional().String(nil)
+	ship.Run(func(ctx context.Context) error {
+		called++
+		return nil
+	})
+	ctx := context.Background()
+	err := app.Parse(ctx, "ship")
+	is.NoErr(err)
+	is.Equal(called, 1)
+	isEqual(t, stderr.String(), "{red}warning:{reset} \"cli ship\" is deprecated, use \"cli deploy\"\n")
+	// Deprecated commands are hidden from the concise help
+	err = app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the app{reset}
+
+`)
+	actual.Reset()
+	err = app.Parse(ctx, "--help")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the app{reset}
+    ship    {dim}ship the app (deprecated: use "cli deploy"){reset}
+
+`)
+}
+
+func TestDeprecatedHelp(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var location string
+	app.Flag("location", "location of the app").String(&location).Default("us")
+	app.Flag("region", "region of the app").Deprecated("use --location").String(&location)
+	app.Arg("name", "name of the app").String(nil)
+	app.Arg("zone", "zone of the app").Deprecated("").Optional().String(nil)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}<name>{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --location <string>  {dim}location of the app (default:"us"){reset}
+
+  {bold}Args:{reset}
+    <name>  {dim}name of the app{reset}
+
+`)
+	actual.Reset()
+	err = app.Parse(ctx, "--help")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}<name>{reset} {dim}[zone]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --location <string>  {dim}location of the app (default:"us"){reset}
+    --region <string>    {dim}region of the app (deprecated: use --location){reset}
+
+  {bold}Args:{reset}
+    <name>  {dim}name of the app{reset}
+    [zone]  {dim}zone of the app (deprecated){reset}
+
+`)
+}
+
+func TestDeprecatedComplete(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Writer(actual)
+	var location string
+	app.Flag("location", "location of the app").DeprecatedName("region").String(&location)
+	deploy := app.Command("deploy", "deploy the app")
+	deploy.Flag("force", "force the deploy").Deprecated("").Bool(nil)
+	app.Command("ship", "ship the app").Deprecated("use deploy")
+	t.Setenv("COMP_LINE", "cli ")
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "deploy\nship\n")
+	actual.Reset()
+	t.Setenv("COMP_LINE", "cli deploy --")
+	err = app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "--location\n--region\n--force\n")
+}
+
+func TestHiddenFlags(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var pprof, dir string
+	app.Flag("pprof-addr", "serve pprof on this address").Hidden().String(&pprof).Default("")
+	app.Flag("dir", "directory").String(&dir).Default(".")
+	app.Arg("token", "internal token").Hidden().Optional().String(nil)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "--help")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --dir <string>  {dim}directory (default:"."){reset}
+
+`)
+	// Hidden flags are still parsed
+	err = app.Parse(ctx, "--pprof-addr", ":6060")
+	is.NoErr(err)
+	is.Equal(pprof, ":6060")
+	// Hidden flags aren't completed
+	actual.Reset()
+	t.Setenv("COMP_LINE", "cli --")
+	err = app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "--dir\n")
+}
+
+func TestAdvancedFlags(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var dir, log string
+	var workers int
+	app.Flag("dir", "directory").String(&dir).Default(".")
+	app.Flag("log", "log level").Group("Logging").String(&log).Default("info")
+	app.Flag("workers", "number of workers").Group("Logging").Advanced().Int(&workers).Default(4)
+	app.Command("deploy", "deploy the app")
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset} {dim}[command]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --dir <string>  {dim}directory (default:"."){reset}
+
+  {bold}Logging Flags:{reset}
+    --log <string>  {dim}log level (default:"info"){reset}
+
+  {bold}Advanced Flags:{reset}
+    --workers <int>  {dim}number of workers (default:"4"){reset}
+
+  {bold}Commands:{reset}
+    deploy  {dim}deploy the app{reset}
+
+`)
+}
+
+func TestHiddenFlagsInfo(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("cli", "desc")
+	app.Flag("pprof-addr", "serve pprof on this address").Hidden().String(nil)
+	app.Flag("workers", "number of workers").Advanced().Int(nil)
+	app.Arg("token", "internal token").Hidden().String(nil)
+	err := app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		flags := cmd.Flags()
+		is.Equal(len(flags), 2)
+		is.True(flags[0].IsHidden())
+		is.True(!flags[0].IsAdvanced())
+		is.True(!flags[1].IsHidden())
+		is.True(flags[1].IsAdvanced())
+		is.True(cmd.Args()[0].IsHidden())
+		return nil
+	})
+	is.NoErr(err)
+}
+
+func TestFlagAlias(t *testing.T) {
+	is := is.New(t)
+	actual := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(actual)
+	var dryRun bool
+	app.Flag("dry-run", "print what would happen").Short('n').Alias("dryrun").Alias("noop").Bool(&dryRun).Default(false)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "--noop")
+	is.NoErr(err)
+	is.Equal(dryRun, true)
+	err = app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, actual.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    -n, --dry-run, --dryrun, --noop  {dim}print what would happen (default:"false"){reset}
+
+`)
+	actual.Reset()
+	t.Setenv("COMP_LINE", "cli --d")
+	err = app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(actual.String(), "--dry-run\n--dryrun\n--noop\n")
+}
+
+func TestFlagAliasDuplicate(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("cli", "desc").Writer(io.Discard)
+	var dryRun, noop bool
+	app.Flag("dry-run", "print what would happen").Alias("noop").Bool(&dryRun)
+	app.Flag("noop", "do nothing").Bool(&noop)
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.True(err != nil)
+	is.Equal(err.Error(), `cli: invalid input "cli" command contains a duplicate flag "--noop"`)
+}
+
+func TestInvocation(t *testing.T) {
+	is := is.New(t)
+	app := cli.New("heroku", "heroku cli").Writer(io.Discard)
+	var app2, region, log string
+	var dynos []string
+	var invocation *cli.Invocation
+	app.Use(func(next func(ctx context.Context) error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			invocation = cli.InvocationFrom(ctx)
+			return next(ctx)
+		}
+	})
+	app.Flag("app", "app name").Short('a').String(&app2)
+	app.Flag("region", "region").Env("HEROKU_REGION").String(&region)
+	app.Flag("log", "log level").String(&log).Default("info")
+	ps := app.Command("ps", "manage dynos").Alias("dynos")
+	scale := ps.Command("scale", "scale dynos")
+	scale.Args("dynos", "dynos to scale").Strings(&dynos)
+	scale.Run(func(ctx context.Context) error { return nil })
+	t.Setenv("HEROKU_REGION", "eu")
+	ctx := context.Background()
+	err := app.Parse(ctx, "dynos", "scale", "-a", "web", "web=1")
+	is.NoErr(err)
+	is.True(invocation != nil)
+	is.Equal(invocation.Command, "heroku ps scale")
+	is.Equal(invocation.Path, []string{"ps", "scale"})
+	is.Equal(invocation.Aliases, []string{"dynos"})
+	is.Equal(invocation.Argv, []string{"dynos", "scale", "-a", "web", "web=1"})
+	is.Equal(len(invocation.Flags), 3)
+	is.Equal(*invocation.Flags[0], cli.Input{Name: "app", Value: "web", Source: cli.Source{Kind: cli.SourceCLI}})
+	is.Equal(*invocation.Flags[1], cli.Input{Name: "region", Value: "eu", Source: cli.Source{Kind: cli.SourceEnv, Env: "HEROKU_REGION"}})
+	is.Equal(*invocation.Flags[2], cli.Input{Name: "log", Value: "info", Source: cli.Source{Kind: cli.SourceDefault}})
+	is.Equal(invocation.Flags[1].Source.String(), "env $HEROKU_REGION")
+	is.Equal(len(invocation.Args), 1)
+	is.Equal(*invocation.Args[0], cli.Input{Name: "dynos", Value: "web=1", Source: cli.Source{Kind: cli.SourceCLI}})
+	// Contexts that didn't come from Parse don't have an invocation
+	is.True(cli.InvocationFrom(ctx) == nil)
+}
+
+func TestInvocationRedactsArgv(t *testing.T) {
+	is := is.New(t)
+	var token, password, region string
+	var verbose bool
+	var invocation *cli.Invocation
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Flag("token", "api token").Short('t').Alias("api-token").Secret().String(&token).Default("")
+	app.Flag("region", "region").String(&region).Default("")
+	deploy := app.Command("deploy", "deploy")
+	deploy.Flag("password", "password").Secret().FromFile().String(&password).Default("")
+	deploy.Flag("verbose", "verbose").Secret().Bool(&verbose).Default(false)
+	deploy.Run(func(ctx context.Context) error {
+		invocation = cli.InvocationFrom(ctx)
+		return nil
+	})
+	ctx := context.Background()
+	argv := []string{"--token=hunter2", "--region", "eu", "deploy", "-t", "hunter3", "--api-token", "hunter4", "--verbose", "--password-file", "pw.txt"}
+	path := filepath.Join(t.TempDir(), "pw.txt")
+	is.NoErr(os.WriteFile(path, []byte("hunter5"), 0644))
+	argv[len(argv)-1] = path
+	err := app.Parse(ctx, argv...)
+	is.NoErr(err)
+	is.Equal(password, "hunter5")
+	is.Equal(invocation.Argv, []string{"--token=***", "--region", "eu", "deploy", "-t", "***", "--api-token", "***", "--verbose", "--password-file", "***"})
+	// The arguments passed to Parse aren't changed
+	is.Equal(argv[0], "--token=hunter2")
+}
+
+func TestValueSource(t *testing.T) {
+	is := is.New(t)
+	var port, workers, retries int
+	var tags []string
+	newApp := func() (*cli.CLI, *cli.Int, *cli.Int, *cli.Int, *cli.OptionalString, *cli.Strings) {
+		app := cli.New("cli", "desc").Writer(io.Discard)
+		portValue := app.Flag("port", "port").Int(&port)
+		portValue.Default(3000)
+		workersValue := app.Flag("workers", "workers").Env("WORKERS").Int(&workers)
+		workersValue.Default(1)
+		retriesValue := app.Flag("retries", "retries").Int(&retries)
+		retriesValue.Default(3)
+		var namePtr *string
+		nameValue := app.Flag("name", "name").Optional().String(&namePtr)
+		tagsValue := app.Args("tags", "tags").Strings(&tags)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app, portValue, workersValue, retriesValue, nameValue, tagsValue
+	}
+	t.Setenv("WORKERS", "4")
+	app, portValue, workersValue, retriesValue, nameValue, tagsValue := newApp()
+	ctx := context.Background()
+	err := app.Parse(ctx, "--port", "8080", "a", "b")
+	is.NoErr(err)
+	is.Equal(portValue.Source(), cli.Source{Kind: cli.SourceCLI})
+	is.True(portValue.IsSet())
+	is.Equal(workersValue.Source(), cli.Source{Kind: cli.SourceEnv, Env: "WORKERS"})
+	is.True(workersValue.IsSet())
+	is.Equal(retriesValue.Source(), cli.Source{Kind: cli.SourceDefault})
+	is.True(!retriesValue.IsSet())
+	is.Equal(nameValue.Source(), cli.Source{Kind: cli.SourceUnset})
+	is.True(!nameValue.IsSet())
+	is.Equal(tagsValue.Source().Kind, cli.SourceCLI)
+	// Passing the default value is still set
+	app, portValue, _, _, _, _ = newApp()
+	err = app.Parse(ctx, "--port=3000", "a")
+	is.NoErr(err)
+	is.Equal(port, 3000)
+	is.Equal(portValue.Source().String(), "cli")
+	is.True(portValue.IsSet())
+}
+
+func TestSourceString(t *testing.T) {
+	is := is.New(t)
+	is.Equal(cli.Source{}.String(), "unset")
+	is.Equal(cli.Source{Kind: cli.SourceCLI}.String(), "cli")
+	is.Equal(cli.Source{Kind: cli.SourceEnv, Env: "PORT"}.String(), "env $PORT")
+	is.Equal(cli.Source{Kind: cli.SourceConfig}.String(), "config")
+	is.Equal(cli.Source{Kind: cli.SourceDefault}.String(), "default")
+	is.Equal(cli.Source{Kind: cli.SourcePrompt}.String(), "prompt")
+}
+
+func TestSourceConfig(t *testing.T) {
+	is := is.New(t)
+	var port int
+	var tags []string
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	portValue := app.Flag("port", "port").Env("PORT").Int(&port)
+	portValue.Default(3000)
+	portValue.Config("8080")
+	tagsValue := app.Args("tags", "tags").Strings(&tags)
+	tagsValue.Config("a b")
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	// The config file's value overrides the default
+	err := app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(port, 8080)
+	is.Equal(portValue.Source(), cli.Source{Kind: cli.SourceConfig})
+	is.True(portValue.IsSet())
+	is.Equal(tags, []string{"a", "b"})
+	is.Equal(tagsValue.Source(), cli.Source{Kind: cli.SourceConfig})
+	// The environment variable overrides the config file's value
+	t.Setenv("PORT", "9090")
+	err = app.Parse(ctx, "c")
+	is.NoErr(err)
+	is.Equal(port, 9090)
+	is.Equal(portValue.Source(), cli.Source{Kind: cli.SourceEnv, Env: "PORT"})
+	is.Equal(tags, []string{"c"})
+	is.Equal(tagsValue.Source(), cli.Source{Kind: cli.SourceCLI})
+	// The command line overrides both
+	err = app.Parse(ctx, "--port", "1")
+	is.NoErr(err)
+	is.Equal(port, 1)
+	is.Equal(portValue.Source(), cli.Source{Kind: cli.SourceCLI})
+	// Invalid config values are reported like invalid input
+	portValue.Config("eighty")
+	os.Unsetenv("PORT")
+	err = app.Parse(ctx)
+	is.True(err != nil)
+}
+
+func TestStdoutStderr(t *testing.T) {
+	is := is.New(t)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Stdout(stdout).Stderr(stderr)
+	app.Command("db", "database commands").Command("migrate", "migrate the database").Run(func(ctx context.Context) error {
+		fmt.Fprintln(cli.StdoutFrom(ctx), "migrating")
+		fmt.Fprintln(cli.StderrFrom(ctx), "done")
+		return nil
+	})
+	ctx := context.Background()
+	// Help that was asked for goes to stdout
+	err := app.Parse(ctx, "db", "-h")
+	is.NoErr(err)
+	is.True(strings.Contains(stdout.String(), "migrate the database"))
+	is.Equal(stderr.String(), "")
+	// Usage that wasn't asked for goes to stderr
+	stdout.Reset()
+	err = app.Parse(ctx, "db")
+	is.NoErr(err)
+	is.Equal(stdout.String(), "")
+	is.True(strings.Contains(stderr.String(), "migrate the database"))
+	// Commands write to the writers from the context
+	stderr.Reset()
+	err = app.Parse(ctx, "db", "migrate")
+	is.NoErr(err)
+	is.Equal(stdout.String(), "migrating\n")
+	is.Equal(stderr.String(), "done\n")
+	// Contexts that didn't come from Parse fall back to os.Stdout and os.Stderr
+	is.Equal(cli.StdoutFrom(ctx), os.Stdout)
+	is.Equal(cli.StderrFrom(ctx), os.Stderr)
+}
+
+// terminal is a fake terminal for stdin
+type terminal struct {
+	io.Reader
+}
+
+func (t *terminal) IsTerminal() bool {
+	return true
+}
+
+func TestInteractive(t *testing.T) {
+	is := is.New(t)
+	stderr := new(bytes.Buffer)
+	var name, format string
+	var port int
+	var tags []string
+	newApp := func(stdin io.Reader) *cli.CLI {
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Writer(io.Discard).Stderr(stderr).Stdin(stdin).Interactive()
+		app.Arg("name", "name of the app").String(&name)
+		app.Args("tags", "tags").Strings(&tags)
+		app.Flag("port", "port to listen on").Int(&port)
+		app.Flag("format", "output format").Enum(&format, "json", "yaml")
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	// Typed values are prompted again on parse errors and enums can be chosen by
+	// number or name
+	stdin := &terminal{strings.NewReader("web\na 'b c'\nabc\n8080\n\n2\n")}
+	err := newApp(stdin).Parse(ctx)
+	is.NoErr(err)
+	is.Equal(name, "web")
+	is.Equal(tags, []string{"a", "b c"})
+	is.Equal(port, 8080)
+	is.Equal(format, "yaml")
+	isEqual(t, stderr.String(), `name of the app (<name>): tags (<tags...>): port to listen on (--port): {red}--port: expected an integer but got "abc"{reset}
+port to listen on (--port): output format (--format)
+  1) json
+  2) yaml
+Choose 1-2: output format (--format)
+  1) json
+  2) yaml
+Choose 1-2: `)
+	// Values passed on the command line aren't prompted for
+	stderr.Reset()
+	stdin = &terminal{strings.NewReader("json\n")}
+	err = newApp(stdin).Parse(ctx, "api", "x", "--port=3000")
+	is.NoErr(err)
+	is.Equal(name, "api")
+	is.Equal(format, "json")
+	is.Equal(stderr.String(), "output format (--format)\n  1) json\n  2) yaml\nChoose 1-2: ")
+	// Closing the input returns the missing error
+	stderr.Reset()
+	stdin = &terminal{strings.NewReader("")}
+	err = newApp(stdin).Parse(ctx)
+	is.True(err != nil)
+	is.Equal(err.Error(), "missing <name>")
+}
+
+func TestInteractiveNotTerminal(t *testing.T) {
+	is := is.New(t)
+	stderr := new(bytes.Buffer)
+	var name string
+	app := cli.New("cli", "desc").Writer(io.Discard).Stderr(stderr).Stdin(strings.NewReader("web\n")).Interactive()
+	app.Arg("name", "name of the app").String(&name)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx)
+	is.True(err != nil)
+	is.Equal(err.Error(), "missing <name>")
+	is.Equal(stderr.String(), "")
+}
+
+func TestSecretFlag(t *testing.T) {
+	is := is.New(t)
+	var token string
+	var pin int
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	var invocation *cli.Invocation
+	newApp := func() *cli.CLI {
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(stderr)
+		app.Flag("token", "api token").Env("API_TOKEN").Secret().String(&token).Default("s3cr3t")
+		app.Flag("pin", "pin").Secret().Int(&pin).Default(0)
+		app.Run(func(ctx context.Context) error {
+			invocation = cli.InvocationFrom(ctx)
+			return nil
+		})
+		return app
+	}
+	ctx := context.Background()
+	// Help hides the default
+	err := newApp().Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --pin <int>       {dim}pin (default:"***"){reset}
+    --token <string>  {dim}api token (or $API_TOKEN, default:"***"){reset}
+
+`)
+	// Invocation hides the value
+	t.Setenv("API_TOKEN", "hunter2")
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(token, "hunter2")
+	is.Equal(invocation.Flags[0].Name, "token")
+	is.Equal(invocation.Flags[0].Value, "***")
+	is.Equal(invocation.Flags[0].Source.Kind, cli.SourceEnv)
+	is.Equal(stderr.String(), "")
+	// Passing the secret on the command line warns
+	err = newApp().Parse(ctx, "--token", "hunter3", "--pin=1234")
+	is.NoErr(err)
+	is.Equal(token, "hunter3")
+	is.Equal(pin, 1234)
+	isEqual(t, stderr.String(), "{red}warning:{reset} --token is a secret, use $API_TOKEN to keep it out of your shell history\n"+
+		"{red}warning:{reset} --pin is a secret, passing it on the command line leaves it in your shell history\n")
+	// Errors hide the value
+	err = newApp().Parse(ctx, "--pin=abcd")
+	is.True(err != nil)
+	is.Equal(err.Error(), "--pin: invalid value ***")
+}
+
+func TestSecretFlagEnvError(t *testing.T) {
+	is := is.New(t)
+	var pin int
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Flag("pin", "pin").Env("PIN").Secret().Int(&pin)
+	t.Setenv("PIN", "abcd")
+	err := app.Parse(context.Background())
+	is.True(err != nil)
+	is.Equal(err.Error(), "--pin: invalid value ***")
+}
+
+func TestSecretFlagInfo(t *testing.T) {
+	is := is.New(t)
+	var token string
+	app := cli.New("cli", "desc")
+	app.Flag("token", "api token").Secret().String(&token).Default("s3cr3t")
+	err := app.Walk(func(path []string, info cli.CommandInfo) error {
+		flag := info.Flags()[0]
+		is.True(flag.IsSecret())
+		def, ok := flag.Default()
+		is.True(ok)
+		is.Equal(def, "***")
+		return nil
+	})
+	is.NoErr(err)
+}
+
+func TestSecretPrompt(t *testing.T) {
+	is := is.New(t)
+	var pin int
+	stderr := new(bytes.Buffer)
+	stdin := &terminal{strings.NewReader("abcd\n1234\n")}
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(io.Discard).Stderr(stderr).Stdin(stdin).Interactive()
+	app.Flag("pin", "pin").Secret().Int(&pin)
+	app.Run(func(ctx context.Context) error { return nil })
+	err := app.Parse(context.Background())
+	is.NoErr(err)
+	is.Equal(pin, 1234)
+	isEqual(t, stderr.String(), "pin (--pin): {red}--pin: invalid value ***{reset}\npin (--pin): ")
+}
+
+func TestFlagFromFile(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "password")
+	err := os.WriteFile(path, []byte("hunter2\n"), 0600)
+	is.NoErr(err)
+	var password string
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	newApp := func() *cli.CLI {
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(stderr)
+		app.Flag("pw", "password").Env("PW").FromFile().Secret().String(&password)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	// Help lists the ways to pass a file
+	err = newApp().Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --pw <string>  {dim}password (or --pw-file, or $PW, or $PW_FILE){reset}
+
+`)
+	// $NAME_FILE
+	t.Setenv("PW_FILE", path)
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(password, "hunter2")
+	// $NAME takes precedence over $NAME_FILE and @path values are literal
+	t.Setenv("PW", "@"+path)
+	password = ""
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(password, "@"+path)
+	// --name-file
+	password = ""
+	err = newApp().Parse(ctx, "--pw-file", path)
+	is.NoErr(err)
+	is.Equal(password, "hunter2")
+	// @path on the command line doesn't warn about the secret
+	password = ""
+	err = newApp().Parse(ctx, "--pw=@"+path)
+	is.NoErr(err)
+	is.Equal(password, "hunter2")
+	is.Equal(stderr.String(), "")
+	// @@ escapes a literal @
+	err = newApp().Parse(ctx, "--pw=@@bc123!")
+	is.NoErr(err)
+	is.Equal(password, "@bc123!")
+	// Missing files return an error naming the flag and the file
+	err = newApp().Parse(ctx, "--pw-file", filepath.Join(dir, "missing"))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "--pw: unable to read --pw-file: open "))
+	err = newApp().Parse(ctx, "--pw=@"+filepath.Join(dir, "missing"))
+	is.True(err != nil)
+	is.True(strings.HasPrefix(err.Error(), "--pw: unable to read \"@"))
+	t.Setenv("PW", "")
+	os.Unsetenv("PW")
+	t.Setenv("PW_FILE", filepath.Join(dir, "missing"))
+	err = newApp().Parse(ctx)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "--pw: unable to read $PW_FILE: open "))
+}
+
+func TestEnvFiles(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "port")
+	err := os.WriteFile(path, []byte("8080\r\n"), 0600)
+	is.NoErr(err)
+	var port int
+	var name string
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard).EnvFiles()
+	app.Flag("port", "port").Env("PORT").Int(&port)
+	app.Arg("name", "name").Env("NAME").String(&name)
+	app.Run(func(ctx context.Context) error { return nil })
+	t.Setenv("PORT_FILE", path)
+	t.Setenv("NAME", "@web")
+	err = app.Parse(context.Background())
+	is.NoErr(err)
+	is.Equal(port, 8080)
+	// @path values are only read on the command line
+	is.Equal(name, "@web")
+}
+
+func TestFileArg(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.txt")
+	err := os.WriteFile(path, []byte("from file"), 0600)
+	is.NoErr(err)
+	var input io.ReadCloser
+	var data []byte
+	newApp := func(stdin io.Reader) *cli.CLI {
+		input = nil
+		app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard).Stdin(stdin)
+		app.Arg("input", "input file").File(&input)
+		app.Run(func(ctx context.Context) error {
+			defer input.Close()
+			data, err = io.ReadAll(input)
+			return err
+		})
+		return app
+	}
+	ctx := context.Background()
+	err = newApp(nil).Parse(ctx, path)
+	is.NoErr(err)
+	is.Equal(string(data), "from file")
+	// "-" reads from stdin
+	err = newApp(strings.NewReader("from stdin")).Parse(ctx, "-")
+	is.NoErr(err)
+	is.Equal(string(data), "from stdin")
+	// Errors name the argument
+	err = newApp(nil).Parse(ctx, filepath.Join(dir, "missing.txt"))
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("<input>: %q does not exist", filepath.Join(dir, "missing.txt")))
+	err = newApp(nil).Parse(ctx, dir)
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("<input>: %q is a directory", dir))
+}
+
+func TestFileFlagLazy(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "input.txt")
+	err := os.WriteFile(path, []byte("from file"), 0600)
+	is.NoErr(err)
+	var input io.ReadCloser
+	var name string
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Flag("input", "input file").File(&input)
+	app.Flag("name", "name").String(&name)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	// The file is checked, but not opened when the parse fails
+	err = app.Parse(ctx, "--input", path)
+	is.True(err != nil)
+	is.Equal(err.Error(), "missing --name")
+	is.NoErr(input.Close())
+	// The file is opened on the first read
+	err = app.Parse(ctx, "--input", path, "--name=web")
+	is.NoErr(err)
+	is.NoErr(os.Remove(path))
+	_, err = io.ReadAll(input)
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("--input: unable to open %q: no such file or directory", path))
+	is.NoErr(input.Close())
+}
+
+func TestPathFlags(t *testing.T) {
+	is := is.New(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	err := os.WriteFile(filepath.Join(home, "config.json"), []byte("{}"), 0600)
+	is.NoErr(err)
+	var path, dir, file string
+	stdout := new(bytes.Buffer)
+	newApp := func() *cli.CLI {
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(io.Discard)
+		app.Flag("out", "output path").Path(&path).Default("~/out")
+		app.Flag("dir", "working directory").Dir(&dir)
+		app.Flag("config", "config file").ExistingFile(&file)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	err = newApp().Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --config <file>  {dim}config file{reset}
+    --dir <dir>      {dim}working directory{reset}
+    --out <path>     {dim}output path (default:"~/out"){reset}
+
+`)
+	// "~" is expanded
+	err = newApp().Parse(ctx, "--dir=~", "--config", "~/config.json")
+	is.NoErr(err)
+	is.Equal(path, filepath.Join(home, "out"))
+	is.Equal(dir, home)
+	is.Equal(file, filepath.Join(home, "config.json"))
+	// Existence is checked
+	err = newApp().Parse(ctx, "--dir=~/config.json", "--config", "~/config.json")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--dir: "~/config.json" is not a directory`)
+	err = newApp().Parse(ctx, "--dir=~", "--config", "~/missing.json")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--config: "~/missing.json" does not exist`)
+}
+
+func TestFilePathReset(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "input.txt")
+	err := os.WriteFile(path, []byte("from file"), 0600)
+	is.NoErr(err)
+	var input io.ReadCloser
+	var out string
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Flag("input", "input file").File(&input)
+	app.Flag("out", "output path").Path(&out)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err = app.Parse(ctx, "--input", path, "--out", "out.txt")
+	is.NoErr(err)
+	is.True(input != nil)
+	is.NoErr(input.Close())
+	// The reader and the path from the previous parse don't leak
+	err = app.Parse(ctx)
+	is.True(err != nil)
+	is.Equal(input, nil)
+	is.Equal(out, "")
+}
+
+func TestArgsDirs(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	err := os.WriteFile(file, nil, 0600)
+	is.NoErr(err)
+	var dirs []string
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Args("dirs", "directories").Dirs(&dirs)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err = app.Parse(ctx, dir, dir)
+	is.NoErr(err)
+	is.Equal(dirs, []string{dir, dir})
+	err = app.Parse(ctx, dir, file)
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("<dirs...>: %q is not a directory", file))
+}
+
+func TestPathsGlob(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.txt"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0600)
+		is.NoErr(err)
+	}
+	var files []string
+	newApp := func() *cli.CLI {
+		files = nil
+		app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+		app.Args("files", "files to format").ExistingFiles(&files).Glob()
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	err := newApp().Parse(ctx, filepath.Join(dir, "*.go"), filepath.Join(dir, "c.txt"))
+	is.NoErr(err)
+	is.Equal(files, []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "c.txt")})
+	err = newApp().Parse(ctx, filepath.Join(dir, "*.rs"))
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("<files...>: no paths match %q", filepath.Join(dir, "*.rs")))
+	err = newApp().Parse(ctx, filepath.Join(dir, "d.go"))
+	is.True(err != nil)
+	is.Equal(err.Error(), fmt.Sprintf("<files...>: %q does not exist", filepath.Join(dir, "d.go")))
+}
+
+func TestBytes(t *testing.T) {
+	is := is.New(t)
+	var cache int64
+	var upload *int64
+	var buffers []int64
+	stdout := new(bytes.Buffer)
+	newApp := func() *cli.CLI {
+		upload = nil
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(io.Discard)
+		app.Flag("cache", "cache size").Env("CACHE_SIZE").Bytes(&cache).Default(512 << 20)
+		app.Flag("upload", "upload limit").Optional().Bytes(&upload)
+		app.Flag("buffer", "buffer sizes").ByteSizes(&buffers).Default(4<<10, 64e3)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	err := newApp().Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --buffer <size>  {dim}buffer sizes (default:"4KiB, 64KB"){reset}
+    --cache <size>   {dim}cache size (or $CACHE_SIZE, default:"512MiB"){reset}
+    --upload <size>  {dim}upload limit (optional){reset}
+
+`)
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(cache, int64(512<<20))
+	is.Equal(upload, nil)
+	is.Equal(buffers, []int64{4 << 10, 64e3})
+	err = newApp().Parse(ctx, "--cache=10MB", "--upload", "1.5gib", "--buffer=512", "--buffer=2KiB")
+	is.NoErr(err)
+	is.Equal(cache, int64(10e6))
+	is.Equal(*upload, int64(1.5*(1<<30)))
+	is.Equal(buffers, []int64{512, 2 << 10})
+	t.Setenv("CACHE_SIZE", "1 TB")
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(cache, int64(1e12))
+	// Single letter units are IEC units
+	err = newApp().Parse(ctx, "--cache=10K", "--upload", "1.5g")
+	is.NoErr(err)
+	is.Equal(cache, int64(10<<10))
+	is.Equal(*upload, int64(1.5*(1<<30)))
+	err = newApp().Parse(ctx, "--cache=10XB")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--cache: expected a byte size like 10MB, 512KiB or 1G but got "10XB"`)
+	err = newApp().Parse(ctx, "--cache=16EiB")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--cache: expected a byte size like 10MB, 512KiB or 1G but got "16EiB"`)
+	err = newApp().Parse(ctx, "--cache=-5MB")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--cache: expected a byte size that isn't negative but got "-5MB"`)
+}
+
+func TestBytesArg(t *testing.T) {
+	is := is.New(t)
+	var size int64
+	var sizes []int64
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard)
+	app.Arg("size", "size").Bytes(&size)
+	app.Args("sizes", "sizes").ByteSizes(&sizes)
+	app.Run(func(ctx context.Context) error { return nil })
+	err := app.Parse(context.Background(), "1kb", "1KiB", "3")
+	is.NoErr(err)
+	is.Equal(size, int64(1000))
+	is.Equal(sizes, []int64{1024, 3})
+}
+
+func TestTime(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var since time.Time
+	var until *time.Time
+	var at []time.Time
+	stdout := new(bytes.Buffer)
+	newApp := func() *cli.CLI {
+		until = nil
+		app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(io.Discard).Clock(func() time.Time { return now })
+		app.Flag("since", "show logs since").Env("SINCE").Time(&since).Default(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+		app.Flag("until", "show logs until").Optional().Time(&until, "Jan 2 15:04")
+		app.Flag("at", "times").Optional().Times(&at)
+		app.Run(func(ctx context.Context) error { return nil })
+		return app
+	}
+	ctx := context.Background()
+	err := newApp().Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --at <time>     {dim}times (optional){reset}
+    --since <time>  {dim}show logs since (or $SINCE, default:"2026-01-01T00:00:00Z"){reset}
+    --until <time>  {dim}show logs until (optional){reset}
+
+`)
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(since, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	is.Equal(until, nil)
+	// Relative times resolve against the clock
+	err = newApp().Parse(ctx, "--since=2h", "--until", "now", "--at=-3d", "--at=+1w")
+	is.NoErr(err)
+	is.Equal(since, now.Add(-2*time.Hour))
+	is.Equal(*until, now)
+	is.Equal(at, []time.Time{now.AddDate(0, 0, -3), now.AddDate(0, 0, 7)})
+	// Absolute times and custom layouts
+	at = nil
+	err = newApp().Parse(ctx, "--since=2026-10-01", "--until", "Oct 5 08:30", "--at=2026-10-02T03:04:05+02:00")
+	is.NoErr(err)
+	is.Equal(since, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	is.Equal(*until, time.Date(0, 10, 5, 8, 30, 0, 0, time.UTC))
+	is.Equal(at[0].Unix(), time.Date(2026, 10, 2, 1, 4, 5, 0, time.UTC).Unix())
+	t.Setenv("SINCE", "1d")
+	err = newApp().Parse(ctx)
+	is.NoErr(err)
+	is.Equal(since, now.AddDate(0, 0, -1))
+	err = newApp().Parse(ctx, "--since=yesterday")
+	is.True(err != nil)
+	is.Equal(err.Error(), `--since: expected a time like 2006-01-02, 2h or now but got "yesterday"`)
+}
+
+func TestTimeClockOncePerParse(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	reads := 0
+	var from, to time.Time
+	var at []time.Time
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard).Clock(func() time.Time {
+		reads++
+		return now.Add(time.Duration(reads) * time.Millisecond)
+	})
+	app.Flag("from", "from").Time(&from)
+	app.Flag("to", "to").Time(&to)
+	app.Flag("at", "at").Optional().Times(&at)
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "--from", "2h", "--to", "1h", "--at", "now", "--at", "now")
+	is.NoErr(err)
+	is.Equal(reads, 1)
+	is.Equal(to.Sub(from), time.Hour)
+	is.Equal(at[0], at[1])
+	// The next parse reads the clock again
+	err = app.Parse(ctx, "--from", "now", "--to", "now")
+	is.NoErr(err)
+	is.Equal(reads, 2)
+	is.Equal(from, now.Add(2*time.Millisecond))
+}
+
+func TestTimeDefaultRelative(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var since time.Time
+	var until *time.Time
+	stdout := new(bytes.Buffer)
+	app := cli.New("cli", "desc").Color(cli.ColorAlways).Stdout(stdout).Stderr(io.Discard).Clock(func() time.Time { return now })
+	sinceValue := app.Flag("since", "show logs since").Time(&since)
+	sinceValue.DefaultRelative("24h")
+	app.Flag("until", "show logs until").Optional().Time(&until).DefaultRelative("+1h")
+	app.Run(func(ctx context.Context) error { return nil })
+	ctx := context.Background()
+	err := app.Parse(ctx, "-h")
+	is.NoErr(err)
+	isEqual(t, stdout.String(), `
+  {bold}Usage:{reset}
+    {dim}${reset} cli {dim}[flags]{reset}
+
+  {bold}Description:{reset}
+    desc
+
+  {bold}Flags:{reset}
+    --since <time>  {dim}show logs since (default:"24h"){reset}
+    --until <time>  {dim}show logs until (default:"+1h"){reset}
+
+`)
+	err = app.Parse(ctx)
+	is.NoErr(err)
+	is.Equal(since, now.Add(-24*time.Hour))
+	is.Equal(*until, now.Add(time.Hour))
+	is.Equal(sinceValue.Source(), cli.Source{Kind: cli.SourceDefault})
+	// Invalid relative defaults panic during setup
+	invalid := func() (msg string) {
+		defer func() { msg = fmt.Sprint(recover()) }()
+		sinceValue.DefaultRelative("yesterday")
+		return ""
+	}
+	is.Equal(invalid(), `cli: invalid relative default "yesterday"`)
+}
+
+func TestTimeArg(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	var from time.Time
+	var to *time.Time
+	app := cli.New("cli", "desc").Stdout(io.Discard).Stderr(io.Discard).Clock(func() time.Time { return now })
+	app.Arg("from", "from").Time(&from)
+	app.Arg("to", "to").Optional().Time(&to)
+	app.Run(func(ctx context.Context) error { return nil })
+	err := app.Parse(context.Background(), "1mo", "now")
+	is.NoErr(err)
+	is.Equal(from, now.Add(-30*24*time.Hour))
+	is.Equal(*to, now)
+}
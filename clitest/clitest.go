@@ -0,0 +1,189 @@
+// Package clitest runs a CLI in-process and compares its output against golden
+// files. To write the golden files, set $CLITEST_UPDATE=1 or define an -update
+// flag in your test package and run the tests with -update:
+//
+//	var _ = flag.Bool("update", false, "update the golden files")
+package clitest
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/livebud/cli"
+	"github.com/matthewmueller/diff"
+)
+
+// updating is true when the golden files should be written. The -update flag
+// isn't registered here, since test packages often define their own.
+func updating() bool {
+	if update, err := strconv.ParseBool(os.Getenv("CLITEST_UPDATE")); err == nil && update {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	update, _ := strconv.ParseBool(f.Value.String())
+	return update
+}
+
+// New runner for the CLI. The output is uncolored and wrapped at 80 columns,
+// so it doesn't depend on the terminal running the tests.
+//
+// The runner takes ownership of the app: Run replaces the app's stdin, stdout,
+// stderr, width and color mode and doesn't restore them afterwards.
+func New(app *cli.CLI) *Runner {
+	return &Runner{app: app, env: map[string]string{}, width: 80}
+}
+
+// Runner runs a CLI with fake inputs and captures its output
+type Runner struct {
+	app      *cli.CLI
+	env      map[string]string
+	stdin    io.Reader
+	width    int
+	terminal bool
+}
+
+// Env sets environment variables with t.Setenv when the CLI runs
+func (r *Runner) Env(env map[string]string) *Runner {
+	for key, value := range env {
+		r.env[key] = value
+	}
+	return r
+}
+
+// Stdin sets the input that commands read from
+func (r *Runner) Stdin(stdin io.Reader) *Runner {
+	r.stdin = stdin
+	return r
+}
+
+// Terminal makes stdin behave like a terminal, so interactive CLIs prompt for
+// missing values
+func (r *Runner) Terminal() *Runner {
+	r.terminal = true
+	return r
+}
+
+// terminal is a fake terminal for stdin
+type terminal struct {
+	io.Reader
+}
+
+func (t *terminal) IsTerminal() bool {
+	return true
+}
+
+// Width sets the terminal width used to wrap the help
+func (r *Runner) Width(width int) *Runner {
+	r.width = width
+	return r
+}
+
+// Result of running the CLI
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+// Run the CLI with the arguments. Tests that use Env can't run in parallel.
+// The app's stdin, stdout, stderr, width and color mode are left pointing at
+// the runner's fakes.
+func (r *Runner) Run(t testing.TB, args ...string) *Result {
+	t.Helper()
+	// Don't complete when the tests are run from a completion
+	t.Setenv("COMP_LINE", "")
+	for key, value := range r.env {
+		t.Setenv(key, value)
+	}
+	stdin := r.stdin
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	if r.terminal {
+		stdin = &terminal{stdin}
+	}
+	stdout, stderr := new(strings.Builder), new(strings.Builder)
+	r.app.Stdout(stdout).Stderr(stderr).Stdin(stdin).Width(r.width).Color(cli.ColorNever)
+	err := r.app.Parse(context.Background(), args...)
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode(err),
+		Err:      err,
+	}
+}
+
+// exitCode is 0 on success, the error's exit code if it has one, otherwise 1
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
+
+// GoldenHelp compares the --help output of every visible command against the
+// golden files in dir, e.g. "testdata/app.golden" and
+// "testdata/app_deploy.golden".
+func (r *Runner) GoldenHelp(t testing.TB, dir string) {
+	t.Helper()
+	type page struct {
+		name string
+		args []string
+	}
+	var pages []page
+	r.app.Walk(func(path []string, cmd cli.CommandInfo) error {
+		if cmd.IsHidden() {
+			return cli.SkipCommand
+		}
+		args := append(append([]string{}, path...), "--help")
+		pages = append(pages, page{strings.ReplaceAll(cmd.Full(), " ", "_"), args})
+		return nil
+	})
+	for _, page := range pages {
+		result := r.Run(t, page.args...)
+		if result.Err != nil {
+			t.Fatalf("clitest: unable to show help for %q: %v", page.name, result.Err)
+		}
+		Golden(t, filepath.Join(dir, page.name+".golden"), result.Stdout)
+	}
+}
+
+// Golden compares the actual output against the golden file at path. Run the
+// tests with -update or $CLITEST_UPDATE=1 to write the golden file instead.
+func Golden(t testing.TB, path, actual string) {
+	t.Helper()
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expect, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("clitest: missing golden file %q, run the tests with -update or $CLITEST_UPDATE=1 to create it", path)
+		}
+		t.Fatal(err)
+	}
+	if err := diff.String(actual, string(expect)); err != nil {
+		t.Fatalf("clitest: %s doesn't match:\n%s", path, err)
+	}
+}
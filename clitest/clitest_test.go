@@ -0,0 +1,115 @@
+package clitest_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/livebud/cli"
+	"github.com/livebud/cli/clitest"
+	"github.com/matryer/is"
+)
+
+// Test packages can define their own -update flag without clitest panicking
+var _ = flag.Bool("update", false, "update the golden files")
+
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func (e exitError) ExitCode() int {
+	return int(e)
+}
+
+type app struct {
+	Region string
+	Force  bool
+	Input  string
+}
+
+func (a *app) CLI() *cli.CLI {
+	root := cli.New("app", "an app to test")
+	root.Flag("region", "region to deploy to").Env("REGION").String(&a.Region).Default("us")
+	deploy := root.Command("deploy", "deploy the app to every region that's configured in the project")
+	deploy.Flag("force", "force the deploy").Bool(&a.Force).Default(false)
+	deploy.Run(func(ctx context.Context) error {
+		fmt.Fprintf(cli.StdoutFrom(ctx), "deploying to %s\n", a.Region)
+		if a.Force {
+			fmt.Fprintln(cli.StderrFrom(ctx), "skipping checks")
+		}
+		return nil
+	})
+	root.Command("read", "read from stdin").Run(func(ctx context.Context) error {
+		input, err := io.ReadAll(cli.StdinFrom(ctx))
+		a.Input = string(input)
+		return err
+	})
+	root.Command("fail", "always fails").Run(func(ctx context.Context) error {
+		return exitError(3)
+	})
+	root.Command("internal", "internal command").Hidden()
+	return root
+}
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+	app := new(app)
+	result := clitest.New(app.CLI()).Env(map[string]string{"REGION": "eu"}).Run(t, "deploy", "--force")
+	is.NoErr(result.Err)
+	is.Equal(result.ExitCode, 0)
+	is.Equal(result.Stdout, "deploying to eu\n")
+	is.Equal(result.Stderr, "skipping checks\n")
+	is.Equal(app.Region, "eu")
+	is.Equal(app.Force, true)
+}
+
+func TestRunStdin(t *testing.T) {
+	is := is.New(t)
+	app := new(app)
+	result := clitest.New(app.CLI()).Stdin(strings.NewReader("hello")).Run(t, "read")
+	is.NoErr(result.Err)
+	is.Equal(app.Input, "hello")
+}
+
+func TestRunExitCode(t *testing.T) {
+	is := is.New(t)
+	app := new(app)
+	runner := clitest.New(app.CLI())
+	result := runner.Run(t, "fail")
+	is.Equal(result.ExitCode, 3)
+	is.Equal(result.Err.Error(), "exit status 3")
+	result = runner.Run(t, "deploy", "extra")
+	is.Equal(result.ExitCode, 1)
+}
+
+func TestRunWidth(t *testing.T) {
+	is := is.New(t)
+	app := new(app)
+	result := clitest.New(app.CLI()).Width(40).Run(t, "-h")
+	is.NoErr(result.Err)
+	clitest.Golden(t, "testdata/narrow.golden", result.Stdout)
+}
+
+func TestGoldenHelp(t *testing.T) {
+	app := new(app)
+	clitest.New(app.CLI()).GoldenHelp(t, "testdata")
+}
+
+func TestGoldenUpdateEnv(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "out.golden")
+	t.Setenv("CLITEST_UPDATE", "1")
+	clitest.Golden(t, path, "hello\n")
+	data, err := os.ReadFile(path)
+	is.NoErr(err)
+	is.Equal(string(data), "hello\n")
+	t.Setenv("CLITEST_UPDATE", "")
+	clitest.Golden(t, path, "hello\n")
+}
@@ -1,30 +1,122 @@
 package cli
 
 import (
+	"io"
 	"os"
 	"text/template"
+
+	"golang.org/x/term"
+)
+
+// ColorMode controls whether the help output is colored
+type ColorMode int
+
+const (
+	// ColorAuto colors the output when writing to a terminal
+	ColorAuto ColorMode = iota
+	// ColorAlways colors the output
+	ColorAlways
+	// ColorNever doesn't color the output
+	ColorNever
 )
 
-var reset = color("\033[0m")
-var dim = color("\033[37m")
+func (m ColorMode) String() string {
+	switch m {
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
+
+func parseColorMode(mode string) ColorMode {
+	switch mode {
+	case "always":
+		return ColorAlways
+	case "never":
+		return ColorNever
+	default:
+		return ColorAuto
+	}
+}
+
+// Color sets whether the help output is colored. By default the output is
+// colored when writing to a terminal.
+func (c *CLI) Color(mode ColorMode) *CLI {
+	c.config.color = mode
+	return c
+}
 
-var colors = template.FuncMap{
-	"reset":     reset,
-	"bold":      color("\033[1m"),
-	"dim":       dim,
-	"underline": color("\033[4m"),
-	"teal":      color("\033[36m"),
-	"blue":      color("\033[34m"),
-	"yellow":    color("\033[33m"),
-	"red":       color("\033[31m"),
-	"green":     color("\033[32m"),
+// ColorFlag adds a --color=auto|always|never flag to every command that
+// overrides the color mode. Commands that already have a --color flag keep
+// their own.
+func (c *CLI) ColorFlag() *CLI {
+	c.Walk(func(path []string, info CommandInfo) error {
+		cmd := info.(*commandInfo).c
+		if cmd.hasFlag("color") {
+			return nil
+		}
+		flag := cmd.Flag("color", "when to color the output")
+		flag.Optional().Enum(&c.config.colorFlag, "auto", "always", "never")
+		return nil
+	})
+	return c
 }
 
-var nocolor = os.Getenv("NO_COLOR") != ""
+// colorEnabled resolves the color mode for the writer
+func (c *config) colorEnabled(w io.Writer) bool {
+	mode := c.color
+	if c.colorFlag != nil {
+		mode = parseColorMode(*c.colorFlag)
+	}
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	} else if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	} else if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+const resetCode = "\033[0m"
+
+var colorCodes = map[string]string{
+	"reset":     resetCode,
+	"bold":      "\033[1m",
+	"dim":       string(Dim),
+	"underline": "\033[4m",
+	"teal":      "\033[36m",
+	"blue":      "\033[34m",
+	"yellow":    "\033[33m",
+	"red":       "\033[31m",
+	"green":     "\033[32m",
+}
+
+// colors is used to parse templates, the functions are replaced with
+// colorFuncs when rendering
+var colors = colorFuncs(DefaultTheme, true)
+
+func colorFuncs(theme Theme, enabled bool) template.FuncMap {
+	funcs := themeFuncs(theme, enabled)
+	for name, code := range colorCodes {
+		funcs[name] = color(enabled, code)
+	}
+	return funcs
+}
 
-func color(code string) func() string {
+func color(enabled bool, code string) func() string {
 	return func() string {
-		if nocolor {
+		if !enabled {
 			return ""
 		}
 		return code
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -28,35 +29,88 @@ func newCommand(config *config, parent *command, flags []*Flag, name, full, help
 type command struct {
 	config *config
 	fset   *flag.FlagSet
-	stack  []Middleware
+	stack  []*middleware
 	run    func(ctx context.Context) error
 	parsed bool
+	// doesn't inherit middleware from the parents
+	isolated bool
 
 	// state for the template
 	name     string
 	full     string
 	help     string
+	long     string
 	hidden   bool
 	advanced bool
-	commands map[string]*command
-	parent   *command
-	alias    string
-	flags    []*Flag
-	args     []*Arg
-	restArgs *Args // optional, collects the rest of the args
+	// deprecation message
+	deprecated *string
+	commands   map[string]*command
+	parent     *command
+	aliases    []string
+	flags      []*Flag
+	groups     []string // order of the flag groups
+	args       []*Arg
+	restArgs   *Args // optional, collects the rest of the args
+	examples   []*example
 }
 
 var _ Command = (*command)(nil)
 
 func (c *command) printUsage() error {
-	return c.config.usage.Execute(c.config.writer, &usage{c})
+	return c.renderUsage(c.config.stdout, false)
+}
+
+// printVerboseUsage also includes the long descriptions of flags
+func (c *command) printVerboseUsage() error {
+	return c.renderUsage(c.config.stdout, true)
+}
+
+// printErrorUsage prints the usage to stderr when it wasn't asked for
+func (c *command) printErrorUsage() error {
+	return c.renderUsage(c.config.stderr, false)
+}
+
+func (c *command) renderUsage(w io.Writer, verbose bool) error {
+	color := c.config.colorEnabled(w)
+	// Resolve the color functions for this render
+	tpl, err := c.config.usage.Clone()
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(colorFuncs(c.config.theme, color))
+	return tpl.Execute(w, &usage{c, c.config.terminalWidth(w), verbose, color, c.config.theme})
 }
 
 type value interface {
 	flag.Value
 	optional() bool
+	source() *Source
+	placeholder() string
 	verify() error
 	Default() (string, bool)
+	// reset clears the state from the previous parse
+	reset()
+}
+
+// resetValues clears the values left over from the previous parse, so they
+// don't leak into the next one
+func (c *command) resetValues() {
+	for _, flag := range c.flags {
+		if flag.value != nil {
+			flag.value.reset()
+		}
+	}
+	for _, arg := range c.args {
+		if arg.value != nil {
+			arg.value.reset()
+		}
+	}
+	if c.restArgs != nil && c.restArgs.value != nil {
+		c.restArgs.value.reset()
+	}
+	for _, sub := range c.commands {
+		sub.resetValues()
+	}
 }
 
 // Set flags only once
@@ -73,19 +127,50 @@ func (c *command) setFlags() error {
 			return fmt.Errorf("%w %q command flag %q is missing a value setter", ErrInvalidInput, c.full, flag.name)
 		}
 		seen[flag.name] = true
-		c.fset.Var(flag.value, flag.name, flag.help)
+		value := flagValue(c.config, flag)
+		c.fset.Var(value, flag.name, flag.help)
 		if flag.short != "" {
 			if seen[flag.short] {
 				return fmt.Errorf("%w %q command contains a duplicate flag \"-%s\"", ErrInvalidInput, c.full, flag.short)
 			}
 			seen[flag.short] = true
-			c.fset.Var(flag.value, flag.short, flag.help)
+			c.fset.Var(value, flag.short, flag.help)
+		}
+		for _, alias := range flag.aliases {
+			if seen[alias] {
+				return fmt.Errorf("%w %q command contains a duplicate flag \"--%s\"", ErrInvalidInput, c.full, alias)
+			}
+			seen[alias] = true
+			c.fset.Var(value, alias, flag.help)
+		}
+		// Read the flag's value from a file with --name-file
+		if flag.env.file {
+			name := flag.fileKey()
+			if seen[name] {
+				return fmt.Errorf("%w %q command contains a duplicate flag \"--%s\"", ErrInvalidInput, c.full, name)
+			}
+			seen[name] = true
+			c.fset.Var(&fileValue{&cliValue{flag.value}, flag}, name, flag.help)
+		}
+		// Route the deprecated names to the flag's value
+		for _, name := range flag.oldNames {
+			if seen[name] {
+				return fmt.Errorf("%w %q command contains a duplicate flag \"--%s\"", ErrInvalidInput, c.full, name)
+			}
+			seen[name] = true
+			msg := "--" + name + " is deprecated, use --" + flag.name + " instead"
+			c.fset.Var(&deprecatedValue{value, c.config, msg}, name, flag.help)
 		}
 	}
 	return nil
 }
 
 func (c *command) parse(ctx context.Context, args []string) error {
+	// Warn when a deprecated command is used
+	if c.deprecated != nil {
+		c.config.warn(deprecation(strconv.Quote(c.full), *c.deprecated))
+	}
+
 	// Set flags
 	if err := c.setFlags(); err != nil {
 		return err
@@ -105,13 +190,29 @@ func (c *command) parse(ctx context.Context, args []string) error {
 	if err := c.fset.Parse(args); err != nil {
 		// Print usage if the developer used -h or --help
 		if errors.Is(err, flag.ErrHelp) {
+			if isDryRun(ctx) {
+				return nil
+			} else if wantsVerboseHelp(c.fset, args) {
+				return c.printVerboseUsage()
+			}
 			return c.printUsage()
 		}
 		return maybeTrimError(err)
 	}
 
+	// Print the version if --version was passed
+	if c.versionRequested() {
+		if isDryRun(ctx) {
+			return nil
+		}
+		return c.printVersion()
+	}
+
 	// Check if the first argument is a subcommand
 	if sub, ok := c.commands[c.fset.Arg(0)]; ok {
+		if invocation := InvocationFrom(ctx); invocation != nil {
+			invocation.selectCommand(c.fset.Arg(0), sub)
+		}
 		subArgs := c.fset.Args()[1:]
 		if len(dashdash) > 0 {
 			subArgs = append(subArgs, dashdash...)
@@ -135,6 +236,14 @@ func (c *command) parse(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Print the version if --version was passed after an arg
+	if c.versionRequested() {
+		if isDryRun(ctx) {
+			return nil
+		}
+		return c.printVersion()
+	}
+
 	// Add anything after -- as a single argument
 	if len(dashdash) > 0 {
 		restArgs = append(restArgs, strings.Join(dashdash[1:], " "))
@@ -153,37 +262,60 @@ loop:
 						return err
 					}
 				}
+				*c.restArgs.value.source() = Source{Kind: SourceCLI}
 			}
 			break loop
 		}
 		if err := c.args[i].value.Set(arg); err != nil {
 			return err
 		}
+		*c.args[i].value.source() = Source{Kind: SourceCLI}
+		if c.args[i].deprecated != nil {
+			c.config.warn(deprecation(c.args[i].key(), *c.args[i].deprecated))
+		}
 	}
+	// Prompt for missing values when interactive
+	prompter := c.config.prompter(ctx)
 	// Verify that all the args have been set or have default values
-	if err := verifyArgs(c.args); err != nil {
+	if err := verifyArgs(c.args, prompter); err != nil {
 		return err
 	}
 	// Also verify rest args if we have any
 	if c.restArgs != nil {
-		if err := c.restArgs.verify(); err != nil {
+		if err := prompter.ask(c.restArgs.verify(), c.restArgs.key(), c.restArgs.help, c.restArgs.value, true, false); err != nil {
 			return err
 		}
 	}
 	// Verify that all the flags have been set or have default values
-	if err := verifyFlags(c.flags); err != nil {
+	if err := verifyFlags(c.flags, prompter); err != nil {
 		return err
 	}
-	// Print usage if there's no run function defined
+	// Print usage if there's no run function defined. The usage wasn't asked
+	// for, so it's printed to stderr.
 	if c.run == nil {
-		if len(restArgs) == 0 {
-			return c.printUsage()
+		if !isDryRun(ctx) {
+			if err := c.printErrorUsage(); err != nil {
+				return err
+			}
 		}
-		return fmt.Errorf("%w: %s", ErrInvalidInput, c.fset.Arg(0))
+		if len(restArgs) > 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, c.fset.Arg(0))
+		}
+		return nil
 	}
 
-	// Compose the middlewares
-	run := compose(c.run, c.stack...)
+	// Stop before running when verifying examples
+	if isDryRun(ctx) {
+		return nil
+	}
+
+	// Make the resolved flags and args available to the middleware
+	if invocation := InvocationFrom(ctx); invocation != nil {
+		invocation.resolve(c)
+	}
+
+	// Compose the middlewares, starting with the parent's middleware
+	run := compose(c.run, c.middleware()...)
 
 	// Run the command
 	if err := run(ctx); err != nil {
@@ -200,23 +332,86 @@ func (c *command) Run(runner func(ctx context.Context) error) {
 	c.run = runner
 }
 
+// Use adds middleware that wraps this command and its subcommands
 func (c *command) Use(middlewares ...Middleware) Command {
-	c.stack = append(c.stack, middlewares...)
+	for _, fn := range middlewares {
+		c.stack = append(c.stack, &middleware{fn, false})
+	}
 	return c
 }
 
+// UseLocal adds middleware that only wraps this command, not its subcommands
+func (c *command) UseLocal(middlewares ...Middleware) Command {
+	for _, fn := range middlewares {
+		c.stack = append(c.stack, &middleware{fn, true})
+	}
+	return c
+}
+
+// Isolated stops the command from inheriting middleware from its parents
+func (c *command) Isolated() Command {
+	c.isolated = true
+	return c
+}
+
+type middleware struct {
+	fn    Middleware
+	local bool
+}
+
+// middleware returns the middleware that wraps the command, outermost parent
+// first
+func (c *command) middleware() (stack []Middleware) {
+	if c.parent != nil && !c.isolated {
+		stack = c.parent.inheritedMiddleware()
+	}
+	for _, mw := range c.stack {
+		stack = append(stack, mw.fn)
+	}
+	return stack
+}
+
+// inheritedMiddleware returns the middleware that wraps the subcommands
+func (c *command) inheritedMiddleware() (stack []Middleware) {
+	if c.parent != nil && !c.isolated {
+		stack = c.parent.inheritedMiddleware()
+	}
+	for _, mw := range c.stack {
+		if !mw.local {
+			stack = append(stack, mw.fn)
+		}
+	}
+	return stack
+}
+
 func (c *command) Command(name, help string) Command {
-	if c.commands[name] != nil {
-		return c.commands[name]
+	if sub := c.commands[name]; sub != nil {
+		if sub.name != name {
+			panic(fmt.Sprintf("cli: cannot create %q because it's already an alias of %q", c.full+" "+name, sub.full))
+		}
+		return sub
 	}
 	// Copy the flags from the parent command
-	flags := append([]*Flag{}, c.flags...)
+	flags := []*Flag{}
+	for _, flag := range c.flags {
+		if !flag.local {
+			flags = append(flags, flag)
+		}
+	}
 	// Create the subcommand
 	cmd := newCommand(c.config, c, flags, name, c.full+" "+name, help)
 	c.commands[name] = cmd
 	return cmd
 }
 
+// Long sets a longer description that's shown on the command's own help page
+// with both -h and --help. The text is dedented and its paragraphs are reflowed
+// to fit the terminal.
+func (c *command) Long(text string) Command {
+	c.long = text
+	return c
+}
+
 func (c *command) Hidden() Command {
 	c.hidden = true
 	return c
@@ -234,7 +429,7 @@ func (c *command) Alias(name string) Command {
 	if _, ok := c.parent.commands[name]; ok {
 		panic(fmt.Sprintf("cli: cannot alias %q to %q because it already exists", c.full, name))
 	}
-	c.alias = name
+	c.aliases = append(c.aliases, name)
 	// Sets up the routing on the parent to route the alias to this command
 	c.parent.commands[name] = c
 	return c
@@ -242,8 +437,10 @@ func (c *command) Alias(name string) Command {
 
 func (c *command) Arg(name, help string) *Arg {
 	arg := &Arg{
-		name: name,
-		help: help,
+		name:   name,
+		help:   help,
+		env:    &env{key: "<" + name + ">", config: c.config},
+		config: c.config,
 	}
 	c.args = append(c.args, arg)
 	return arg
@@ -256,8 +453,10 @@ func (c *command) Args(name, help string) *Args {
 		panic("cli: you can only use cmd.Args(name, usage) once per command")
 	}
 	args := &Args{
-		name: name,
-		help: help,
+		name:   name,
+		help:   help,
+		env:    &env{key: "<" + name + "...>", config: c.config},
+		config: c.config,
 	}
 	c.restArgs = args
 	return args
@@ -265,13 +464,49 @@ func (c *command) Args(name, help string) *Args {
 
 func (c *command) Flag(name, help string) *Flag {
 	flag := &Flag{
-		name: name,
-		help: help,
+		name:   name,
+		help:   help,
+		env:    &env{key: "--" + name, config: c.config},
+		config: c.config,
 	}
 	c.flags = append(c.flags, flag)
 	return flag
 }
 
+// hasFlag is true when the command has a flag with this name or alias
+func (c *command) hasFlag(name string) bool {
+	for _, flag := range c.flags {
+		if flag.name == name || slices.Contains(flag.aliases, name) || slices.Contains(flag.oldNames, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FlagGroups sets the order that flag groups are shown in the help. Groups
+// that aren't listed are shown afterwards in the order they were added.
+func (c *command) FlagGroups(names ...string) Command {
+	c.groups = names
+	return c
+}
+
+// flagGroups returns the group names in order, inheriting the order from the
+// parent commands
+func (c *command) flagGroups() (groups []string) {
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		if cmd.groups != nil {
+			groups = append(groups, cmd.groups...)
+			break
+		}
+	}
+	for _, flag := range c.flags {
+		if flag.group != "" && !slices.Contains(groups, flag.group) {
+			groups = append(groups, flag.group)
+		}
+	}
+	return groups
+}
+
 func (c *command) Find(cmds ...string) (*command, bool) {
 	if len(cmds) == 0 {
 		return c, true
@@ -284,6 +519,18 @@ func (c *command) Find(cmds ...string) (*command, bool) {
 	return sub.Find(cmds[1:]...)
 }
 
+// wantsVerboseHelp is true when the flag set stopped at --help instead of -h.
+// Other arguments, like flag values, don't count.
+func wantsVerboseHelp(fset *flag.FlagSet, args []string) bool {
+	// The flag set's remaining arguments start right after the help flag
+	i := len(args) - len(fset.Args()) - 1
+	if i < 0 {
+		return false
+	}
+	name, _, _ := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+	return name == "help"
+}
+
 func isFlag(arg string) bool {
 	return strings.HasPrefix(arg, "-") && strings.TrimLeft(arg, "-") != ""
 }
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+)
+
+// Deprecated marks the flag as deprecated. Using the flag prints a warning to
+// stderr, e.g. `Deprecated("use --location")`. Deprecated flags are never
+// required and are only shown in the help when --help is passed. Use
+// DeprecatedName to rename a flag.
+func (f *Flag) Deprecated(msg string) *Flag {
+	f.deprecated = &msg
+	return f
+}
+
+// DeprecatedName keeps an old name of the flag working. Using the old name
+// sets the flag and prints a warning to stderr.
+func (f *Flag) DeprecatedName(name string) *Flag {
+	f.oldNames = append(f.oldNames, strings.TrimPrefix(name, "--"))
+	return f
+}
+
+// DeprecatedEnv keeps an old environment variable working. The old variable
+// is read when the new one isn't set and prints a warning to stderr.
+func (f *Flag) DeprecatedEnv(name string) *Flag {
+	f.env.deprecated = append(f.env.deprecated, strings.TrimPrefix(name, "$"))
+	return f
+}
+
+// Deprecated marks the argument as deprecated. Passing the argument prints a
+// warning to stderr.
+func (a *Arg) Deprecated(msg string) *Arg {
+	a.deprecated = &msg
+	return a
+}
+
+// DeprecatedEnv keeps an old environment variable working. The old variable
+// is read when the new one isn't set and prints a warning to stderr.
+func (a *Arg) DeprecatedEnv(name string) *Arg {
+	a.env.deprecated = append(a.env.deprecated, strings.TrimPrefix(name, "$"))
+	return a
+}
+
+// DeprecatedEnv keeps an old environment variable working. The old variable
+// is read when the new one isn't set and prints a warning to stderr.
+func (a *Args) DeprecatedEnv(name string) *Args {
+	a.env.deprecated = append(a.env.deprecated, strings.TrimPrefix(name, "$"))
+	return a
+}
+
+// Deprecated marks the command as deprecated. Running the command prints a
+// warning to stderr. Deprecated commands are only listed in the help when
+// --help is passed, but are still completed.
+func (c *command) Deprecated(msg string) Command {
+	c.deprecated = &msg
+	return c
+}
+
+// deprecation formats the warning for a deprecated subject
+func deprecation(subject, msg string) string {
+	if msg == "" {
+		return subject + " is deprecated"
+	}
+	return subject + " is deprecated, " + msg
+}
+
+// flagValue returns the value to register in the flag set, warning when a
+// deprecated flag is set
+func flagValue(config *config, f *Flag) flag.Value {
+	var value flag.Value = &cliValue{f.value}
+	if f.env.file {
+		value = &atFileValue{value, f.key()}
+	}
+	if f.secret {
+		value = &secretValue{value, config, f}
+	}
+	if f.deprecated == nil {
+		return value
+	}
+	return &deprecatedValue{value, config, deprecation("--"+f.name, *f.deprecated)}
+}
+
+// deprecatedValue prints a warning before setting the value
+type deprecatedValue struct {
+	flag.Value
+	config *config
+	msg    string
+}
+
+func (v *deprecatedValue) Set(value string) error {
+	v.config.warn(v.msg)
+	return v.Value.Set(value)
+}
+
+// IsBoolFlag allows deprecated boolean flags to be passed without a value
+func (v *deprecatedValue) IsBoolFlag() bool {
+	return isBoolFlag(v.Value)
+}
+
+// deprecatedSuffix is shown after the help of deprecated items in --help
+func deprecatedSuffix(msg *string) string {
+	if *msg == "" {
+		return "deprecated"
+	}
+	return "deprecated: " + *msg
+}
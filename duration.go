@@ -10,14 +10,32 @@ import (
 
 type Duration struct {
 	target *time.Duration
-	envvar *string
+	envvar *env
 	defval *time.Duration
+	source Source // where the value came from
 }
 
 func (v *Duration) Default(value time.Duration) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Duration) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Duration) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Duration) IsSet() bool {
+	return v.source.isSet()
+}
+
 type durationValue struct {
 	key   string
 	inner *Duration
@@ -30,6 +48,19 @@ func (v *durationValue) optional() bool {
 	return false
 }
 
+func (v *durationValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *durationValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *durationValue) placeholder() string {
+	return "duration"
+}
+
 func (v *durationValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -44,9 +75,13 @@ func (v *durationValue) Default() (string, bool) {
 func (v *durationValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -76,14 +111,32 @@ func (v *durationValue) String() string {
 
 type OptionalDuration struct {
 	target **time.Duration
-	envvar *string
+	envvar *env
 	defval *time.Duration
+	source Source // where the value came from
 }
 
 func (v *OptionalDuration) Default(value time.Duration) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalDuration) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalDuration) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalDuration) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalDurationValue struct {
 	key   string
 	inner *OptionalDuration
@@ -96,6 +149,22 @@ func (v *optionalDurationValue) optional() bool {
 	return true
 }
 
+func (v *optionalDurationValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalDurationValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalDurationValue) placeholder() string {
+	return "duration"
+}
+
 func (v *optionalDurationValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -110,9 +179,13 @@ func (v *optionalDurationValue) Default() (string, bool) {
 func (v *optionalDurationValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -142,15 +215,33 @@ func (v *optionalDurationValue) String() string {
 
 type Durations struct {
 	target   *[]time.Duration
-	envvar   *string
+	envvar   *env
 	defval   *[]time.Duration
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Durations) Default(values ...time.Duration) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Durations) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Durations) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Durations) IsSet() bool {
+	return v.source.isSet()
+}
+
 type durationsValue struct {
 	key   string
 	inner *Durations
@@ -163,12 +254,32 @@ func (v *durationsValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *durationsValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *durationsValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *durationsValue) placeholder() string {
+	return "duration"
+}
+
 func (v *durationsValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
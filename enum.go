@@ -9,16 +9,39 @@ import (
 
 type Enum struct {
 	target *string
-	envvar *string
+	envvar *env
 	defval *string // default value
+	source Source  // where the value came from
 }
 
 func (v *Enum) Default(value string) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Enum) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Enum) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Enum) IsSet() bool {
+	return v.source.isSet()
+}
+
+// Env sets the environment variable of the flag or argument
 func (v *Enum) Env(name string) {
-	v.envvar = &name
+	if v.envvar == nil {
+		v.envvar = &env{}
+	}
+	// Keep the config, so deprecated names and files are still checked
+	v.envvar.name = strings.TrimPrefix(name, "$")
 }
 
 type enumValue struct {
@@ -34,6 +57,23 @@ func (v *enumValue) optional() bool {
 	return false
 }
 
+func (v *enumValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *enumValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *enumValue) choices() []string {
+	return v.possibilities
+}
+
+func (v *enumValue) placeholder() string {
+	return strings.Join(v.possibilities, "|")
+}
+
 func (v *enumValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -48,9 +88,13 @@ func (v *enumValue) Default() (string, bool) {
 func (v *enumValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		if err := verifyEnum(v.key, *v.inner.defval, v.possibilities...); err != nil {
 			return err
 		}
@@ -82,16 +126,39 @@ func (v *enumValue) String() string {
 
 type OptionalEnum struct {
 	target **string
-	envvar *string
+	envvar *env
 	defval *string // default value
+	source Source  // where the value came from
 }
 
 func (v *OptionalEnum) Default(value string) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalEnum) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalEnum) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalEnum) IsSet() bool {
+	return v.source.isSet()
+}
+
+// Env sets the environment variable of the flag or argument
 func (v *OptionalEnum) Env(name string) {
-	v.envvar = &name
+	if v.envvar == nil {
+		v.envvar = &env{}
+	}
+	// Keep the config, so deprecated names and files are still checked
+	v.envvar.name = strings.TrimPrefix(name, "$")
 }
 
 type optionalEnumValue struct {
@@ -107,6 +174,22 @@ func (v *optionalEnumValue) optional() bool {
 	return true
 }
 
+func (v *optionalEnumValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalEnumValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalEnumValue) placeholder() string {
+	return strings.Join(v.possibilities, "|")
+}
+
 func (v *optionalEnumValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -121,9 +204,13 @@ func (v *optionalEnumValue) Default() (string, bool) {
 func (v *optionalEnumValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		if err := verifyEnum(v.key, *v.inner.defval, v.possibilities...); err != nil {
 			return err
 		}
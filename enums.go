@@ -9,16 +9,34 @@ import (
 
 type Enums struct {
 	target        *[]string
-	envvar        *string
+	envvar        *env
 	defval        *[]string
 	possibilities []string
 	optional      bool
+	source        Source // where the value came from
 }
 
 func (v *Enums) Default(values ...string) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Enums) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Enums) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Enums) IsSet() bool {
+	return v.source.isSet()
+}
+
 type enumsValue struct {
 	key   string
 	inner *Enums
@@ -31,10 +49,29 @@ func (v *enumsValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *enumsValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *enumsValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *enumsValue) placeholder() string {
+	return strings.Join(v.inner.possibilities, "|")
+}
+
 func (v *enumsValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		fields, err := shellquote.Split(value)
 		if err != nil {
 			return fmt.Errorf("%s: expected a list of strings but got %q", v.key, value)
@@ -46,6 +83,7 @@ func (v *enumsValue) verify() error {
 		}
 		return nil
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		for _, val := range *v.inner.defval {
 			if err := verifyEnum(v.key, val, v.inner.possibilities...); err != nil {
 				return err
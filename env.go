@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+)
+
+// env is the environment variable that sets a flag or an argument
+type env struct {
+	key        string // the flag or argument that's set, used in errors
+	name       string
+	deprecated []string // deprecated names that are checked after the name
+	file       bool     // also read $NAME_FILE
+	config     *config  // used to warn about deprecated names
+	configured *string  // the config file's value, used when no variable is set
+}
+
+func (e *env) String() string {
+	return "$" + e.name
+}
+
+// lookupEnv returns the value and the environment variable that set it. When
+// files are enabled, $NAME_FILE is checked after $NAME and the contents of the
+// file are returned. The config file's value is used when no variable is set.
+func lookupEnv(e *env) (value string, source Source, ok bool, err error) {
+	if e == nil {
+		return "", Source{}, false, nil
+	}
+	if e.name != "" {
+		if value, name, ok, err := e.lookup(e.name); err != nil || ok {
+			return value, Source{Kind: SourceEnv, Env: name}, ok, err
+		}
+	}
+	for _, name := range e.deprecated {
+		value, name, ok, err := e.lookup(name)
+		if err != nil {
+			return "", Source{}, false, err
+		} else if !ok {
+			continue
+		}
+		if e.config != nil {
+			msg := "$" + name + " is deprecated"
+			if e.name != "" {
+				msg += ", use $" + e.name + " instead"
+			}
+			e.config.warn(msg)
+		}
+		return value, Source{Kind: SourceEnv, Env: name}, true, nil
+	}
+	if e.configured != nil {
+		return *e.configured, Source{Kind: SourceConfig}, true, nil
+	}
+	return "", Source{}, false, nil
+}
+
+// lookup the variable, then its _FILE variant when files are enabled
+func (e *env) lookup(name string) (value, found string, ok bool, err error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, name, true, nil
+	} else if !e.readsFiles() {
+		return "", "", false, nil
+	}
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok {
+		return "", "", false, nil
+	}
+	value, err = readFile(e.key, "$"+name+"_FILE", path)
+	return value, name + "_FILE", true, err
+}
+
+// readsFiles is true when $NAME_FILE variants are checked
+func (e *env) readsFiles() bool {
+	return e.file || (e.config != nil && e.config.envFiles)
+}
+
+func hasEnv(e *env) bool {
+	return e != nil && e.name != ""
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kballard/go-shellquote"
+)
+
+type example struct {
+	cmdline string
+	help    string
+}
+
+// Example adds an example to the command's help. The cmdline is relative to
+// the command, so `Example("web=1", "scale to one dyno")` on `heroku ps scale`
+// shows up as `heroku ps scale web=1`.
+func (c *command) Example(cmdline, help string) Command {
+	c.examples = append(c.examples, &example{cmdline, help})
+	return c
+}
+
+type dryRunKey struct{}
+
+// dryRun parses the command line without running commands or printing usage
+func dryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	dry, _ := ctx.Value(dryRunKey{}).(bool)
+	return dry
+}
+
+// VerifyExamples parses every example in the command tree without running any
+// commands. Use this in your tests to catch stale examples. Each example is
+// parsed from a clean slate and sets the flag and argument targets, just like
+// Parse does.
+func (c *CLI) VerifyExamples(ctx context.Context) error {
+	ctx = dryRun(ctx)
+	// Don't print warnings for deprecated or secret inputs in the examples
+	c.config.verifying = true
+	defer func() { c.config.verifying = false }()
+	return c.Walk(func(path []string, info CommandInfo) error {
+		cmd := info.(*commandInfo).c
+		for _, example := range cmd.examples {
+			fields, err := shellquote.Split(example.cmdline)
+			if err != nil {
+				return fmt.Errorf("%w example %q: %w", ErrInvalidInput, joinCommand(cmd.full, example.cmdline), err)
+			}
+			args := append(append([]string{}, path...), fields...)
+			// Each example starts from a clean slate
+			c.reset()
+			if err := c.root.parse(ctx, args); err != nil {
+				return fmt.Errorf("%w example %q: %w", ErrInvalidInput, joinCommand(cmd.full, example.cmdline), err)
+			}
+		}
+		return nil
+	})
+}
+
+func joinCommand(full, cmdline string) string {
+	if cmdline == "" {
+		return full
+	}
+	return full + " " + cmdline
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// File opens the file for reading. "-" reads from stdin. The file is checked
+// when parsing, but only opened on the first read.
+type File struct {
+	target *io.ReadCloser
+	envvar *env
+	defval *string // default path
+	source Source  // where the value came from
+}
+
+func (v *File) Default(path string) {
+	v.defval = &path
+}
+
+// Source returns where the value came from
+func (v *File) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *File) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *File) IsSet() bool {
+	return v.source.isSet()
+}
+
+type fileReaderValue struct {
+	key    string
+	inner  *File
+	config *config // reads stdin for "-"
+	path   string
+	set    bool
+}
+
+var _ value = (*fileReaderValue)(nil)
+
+func (v *fileReaderValue) optional() bool {
+	return false
+}
+
+func (v *fileReaderValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *fileReaderValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	v.path = ""
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *fileReaderValue) placeholder() string {
+	return "file"
+}
+
+func (v *fileReaderValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		return v.open(*v.inner.defval)
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *fileReaderValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *fileReaderValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	return *v.inner.defval, true
+}
+
+func (v *fileReaderValue) Set(val string) error {
+	if err := v.open(val); err != nil {
+		return err
+	}
+	v.set = true
+	return nil
+}
+
+// open checks the file and sets the target to a reader that opens the file
+// lazily, so a parse that fails afterwards doesn't leak an open file
+func (v *fileReaderValue) open(path string) error {
+	if path == "-" {
+		*v.inner.target = io.NopCloser(v.config.stdin)
+		v.path = path
+		return nil
+	}
+	expanded, err := checkPath(v.key, filePath, path)
+	if err != nil {
+		return err
+	}
+	*v.inner.target = &lazyFile{key: v.key, name: path, path: expanded}
+	v.path = path
+	return nil
+}
+
+// lazyFile opens the file on the first read
+type lazyFile struct {
+	key  string
+	name string // the path that was passed in
+	path string // the expanded path
+	file *os.File
+	err  error
+}
+
+func (f *lazyFile) Read(p []byte) (int, error) {
+	if f.file == nil && f.err == nil {
+		file, err := os.Open(f.path)
+		if err != nil {
+			f.err = fmt.Errorf("%s: unable to open %q: %w", f.key, f.name, unwrapPathError(err))
+		}
+		f.file = file
+	}
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.file.Read(p)
+}
+
+// Close the file if it was opened
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+func (v *fileReaderValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return v.path
+	} else if v.hasDefault() {
+		return *v.inner.defval
+	}
+	return ""
+}
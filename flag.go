@@ -1,17 +1,30 @@
 package cli
 
 import (
+	"errors"
+	"io"
 	"net/url"
 	"strings"
 	"time"
 )
 
 type Flag struct {
-	name  string
-	help  string
-	short string
-	env   *string
-	value value
+	name        string
+	help        string
+	long        string
+	short       string
+	aliases     []string // additional long names
+	group       string
+	hidden      bool
+	advanced    bool
+	secret      bool
+	local       bool // not copied to subcommands
+	placeholder *string
+	env         *env
+	config      *config
+	value       value
+	deprecated  *string  // deprecation message
+	oldNames    []string // deprecated names that route to this flag
 }
 
 func (f *Flag) key() string {
@@ -24,10 +37,51 @@ func (f *Flag) Short(short byte) *Flag {
 	return f
 }
 
+// Alias adds another long name for the flag, e.g. `Alias("dryrun")` accepts
+// both --dry-run and --dryrun.
+func (f *Flag) Alias(name string) *Flag {
+	f.aliases = append(f.aliases, strings.TrimPrefix(name, "--"))
+	return f
+}
+
+// Long sets a longer description that's shown below the help when --help is
+// passed. -h keeps the help short and leaves it out. The text is dedented and
+// its paragraphs are reflowed to fit the terminal.
+func (f *Flag) Long(text string) *Flag {
+	f.long = text
+	return f
+}
+
+// Group shows the flag under its own heading in the help, e.g. "Networking
+// Flags:".
+func (f *Flag) Group(name string) *Flag {
+	f.group = name
+	return f
+}
+
+// Hidden omits the flag from the help and completion. Hidden flags are still
+// parsed, which is useful for debug-only flags like --pprof-addr.
+func (f *Flag) Hidden() *Flag {
+	f.hidden = true
+	return f
+}
+
+// Advanced shows the flag under the "Advanced Flags:" heading in the help.
+func (f *Flag) Advanced() *Flag {
+	f.advanced = true
+	return f
+}
+
+// Placeholder overrides the value placeholder shown in the help, e.g.
+// `--config FILE` instead of `--config <string>`.
+func (f *Flag) Placeholder(placeholder string) *Flag {
+	f.placeholder = &placeholder
+	return f
+}
+
 // Env allows you to use an environment variable to set the value of the flag.
 func (f *Flag) Env(name string) *Flag {
-	name = strings.TrimPrefix(name, "$")
-	f.env = &name
+	f.env.name = strings.TrimPrefix(name, "$")
 	return f
 }
 
@@ -36,39 +90,39 @@ func (f *Flag) Optional() *OptionalFlag {
 }
 
 func (f *Flag) Int(target *int) *Int {
-	value := &Int{target, f.env, nil}
+	value := &Int{target: target, envvar: f.env}
 	f.value = &intValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Duration(target *time.Duration) *Duration {
-	value := &Duration{target, f.env, nil}
+	value := &Duration{target: target, envvar: f.env}
 	f.value = &durationValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Url(target *url.URL) *Url {
-	value := &Url{target, f.env, nil}
+	value := &Url{target: target, envvar: f.env}
 	f.value = &urlValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) String(target *string) *String {
-	value := &String{target, f.env, nil}
+	value := &String{target: target, envvar: f.env}
 	f.value = &stringValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Strings(target *[]string) *Strings {
 	*target = []string{}
-	value := &Strings{target, f.env, nil, false}
+	value := &Strings{target: target, envvar: f.env}
 	f.value = &stringsValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Urls(target *[]*url.URL) *Urls {
 	*target = []*url.URL{}
-	value := &Urls{target, f.env, nil, false}
+	value := &Urls{target: target, envvar: f.env}
 	f.value = &urlsValue{key: f.key(), inner: value}
 	return value
 }
@@ -80,52 +134,110 @@ func (f *Flag) Enums(target *[]string, possibilities ...string) *Enums {
 	return value
 }
 
+// Bytes accepts a byte size like 10MB, 512KiB or 1G, where single letter units
+// are IEC units.
+func (f *Flag) Bytes(target *int64) *Bytes {
+	value := &Bytes{target: target, envvar: f.env}
+	f.value = &bytesValue{key: f.key(), inner: value}
+	return value
+}
+
+func (f *Flag) ByteSizes(target *[]int64) *ByteSizes {
+	*target = []int64{}
+	value := &ByteSizes{target: target, envvar: f.env}
+	f.value = &byteSizesValue{key: f.key(), inner: value}
+	return value
+}
+
+// Time accepts RFC3339 times, dates like 2006-01-02, the custom layouts, "now"
+// and times relative to now like 2h, -3d or +1w.
+func (f *Flag) Time(target *time.Time, layouts ...string) *Time {
+	value := &Time{target: target, envvar: f.env}
+	f.value = &timeValue{key: f.key(), inner: value, config: f.config, layouts: layouts}
+	return value
+}
+
+func (f *Flag) Times(target *[]time.Time, layouts ...string) *Times {
+	*target = []time.Time{}
+	value := &Times{target: target, envvar: f.env}
+	f.value = &timesValue{key: f.key(), inner: value, config: f.config, layouts: layouts}
+	return value
+}
+
 func (f *Flag) Durations(target *[]time.Duration) *Durations {
 	*target = []time.Duration{}
-	value := &Durations{target, f.env, nil, false}
+	value := &Durations{target: target, envvar: f.env}
 	f.value = &durationsValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Enum(target *string, possibilities ...string) *Enum {
-	value := &Enum{target, f.env, nil}
+	value := &Enum{target: target, envvar: f.env}
 	f.value = &enumValue{key: f.key(), inner: value, possibilities: possibilities}
 	return value
 }
 
 func (f *Flag) StringMap(target *map[string]string) *StringMap {
 	*target = map[string]string{}
-	value := &StringMap{target, f.env, nil, false}
+	value := &StringMap{target: target, envvar: f.env}
 	f.value = &stringMapValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Int64(target *int64) *Int64 {
-	value := &Int64{target, f.env, nil}
+	value := &Int64{target: target, envvar: f.env}
 	f.value = &int64Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Float32(target *float32) *Float32 {
-	value := &Float32{target, f.env, nil}
+	value := &Float32{target: target, envvar: f.env}
 	f.value = &float32Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Float64(target *float64) *Float64 {
-	value := &Float64{target, f.env, nil}
+	value := &Float64{target: target, envvar: f.env}
 	f.value = &float64Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *Flag) Bool(target *bool) *Bool {
-	value := &Bool{target, f.env, nil}
+	value := &Bool{target: target, envvar: f.env}
 	f.value = &boolValue{key: f.key(), inner: value}
 	return value
 }
 
+// File opens the file for reading, or reads stdin when the path is "-".
+func (f *Flag) File(target *io.ReadCloser) *File {
+	value := &File{target: target, envvar: f.env}
+	f.value = &fileReaderValue{key: f.key(), inner: value, config: f.config}
+	return value
+}
+
+// Path accepts any path and expands a leading "~" to the home directory.
+func (f *Flag) Path(target *string) *Path {
+	value := &Path{target: target, envvar: f.env}
+	f.value = &pathValue{key: f.key(), kind: anyPath, inner: value}
+	return value
+}
+
+// Dir is a path to a directory that must exist.
+func (f *Flag) Dir(target *string) *Path {
+	value := &Path{target: target, envvar: f.env}
+	f.value = &pathValue{key: f.key(), kind: dirPath, inner: value}
+	return value
+}
+
+// ExistingFile is a path to a file that must exist.
+func (f *Flag) ExistingFile(target *string) *Path {
+	value := &Path{target: target, envvar: f.env}
+	f.value = &pathValue{key: f.key(), kind: filePath, inner: value}
+	return value
+}
+
 func (f *Flag) verify(name string) error {
-	return f.value.verify()
+	return verifySecret(f, f.value.verify())
 }
 
 type OptionalFlag struct {
@@ -137,61 +249,73 @@ func (f *OptionalFlag) key() string {
 }
 
 func (f *OptionalFlag) String(target **string) *OptionalString {
-	value := &OptionalString{target, f.f.env, nil}
+	value := &OptionalString{target: target, envvar: f.f.env}
 	f.f.value = &optionalStringValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Int(target **int) *OptionalInt {
-	value := &OptionalInt{target, f.f.env, nil}
+	value := &OptionalInt{target: target, envvar: f.f.env}
 	f.f.value = &optionalIntValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Duration(target **time.Duration) *OptionalDuration {
-	value := &OptionalDuration{target, f.f.env, nil}
+	value := &OptionalDuration{target: target, envvar: f.f.env}
 	f.f.value = &optionalDurationValue{key: f.key(), inner: value}
 	return value
 }
 
+func (f *OptionalFlag) Bytes(target **int64) *OptionalBytes {
+	value := &OptionalBytes{target: target, envvar: f.f.env}
+	f.f.value = &optionalBytesValue{key: f.key(), inner: value}
+	return value
+}
+
+func (f *OptionalFlag) Time(target **time.Time, layouts ...string) *OptionalTime {
+	value := &OptionalTime{target: target, envvar: f.f.env}
+	f.f.value = &optionalTimeValue{key: f.key(), inner: value, config: f.f.config, layouts: layouts}
+	return value
+}
+
 func (f *OptionalFlag) Url(target **url.URL) *OptionalUrl {
-	value := &OptionalUrl{target, f.f.env, nil}
+	value := &OptionalUrl{target: target, envvar: f.f.env}
 	f.f.value = &optionalUrlValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Int64(target **int64) *OptionalInt64 {
-	value := &OptionalInt64{target, f.f.env, nil}
+	value := &OptionalInt64{target: target, envvar: f.f.env}
 	f.f.value = &optionalInt64Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Float32(target **float32) *OptionalFloat32 {
-	value := &OptionalFloat32{target, f.f.env, nil}
+	value := &OptionalFloat32{target: target, envvar: f.f.env}
 	f.f.value = &optionalFloat32Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Float64(target **float64) *OptionalFloat64 {
-	value := &OptionalFloat64{target, f.f.env, nil}
+	value := &OptionalFloat64{target: target, envvar: f.f.env}
 	f.f.value = &optionalFloat64Value{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Bool(target **bool) *OptionalBool {
-	value := &OptionalBool{target, f.f.env, nil}
+	value := &OptionalBool{target: target, envvar: f.f.env}
 	f.f.value = &optionalBoolValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Strings(target *[]string) *Strings {
-	value := &Strings{target, f.f.env, nil, true}
+	value := &Strings{target: target, envvar: f.f.env, optional: true}
 	f.f.value = &stringsValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Urls(target *[]*url.URL) *Urls {
-	value := &Urls{target, f.f.env, nil, true}
+	value := &Urls{target: target, envvar: f.f.env, optional: true}
 	f.f.value = &urlsValue{key: f.key(), inner: value}
 	return value
 }
@@ -204,27 +328,48 @@ func (f *OptionalFlag) Enums(target *[]string, possibilities ...string) *Enums {
 
 func (f *OptionalFlag) Durations(target *[]time.Duration) *Durations {
 	*target = []time.Duration{}
-	value := &Durations{target, f.f.env, nil, true}
+	value := &Durations{target: target, envvar: f.f.env, optional: true}
 	f.f.value = &durationsValue{key: f.key(), inner: value}
 	return value
 }
 
+func (f *OptionalFlag) ByteSizes(target *[]int64) *ByteSizes {
+	*target = []int64{}
+	value := &ByteSizes{target: target, envvar: f.f.env, optional: true}
+	f.f.value = &byteSizesValue{key: f.key(), inner: value}
+	return value
+}
+
+func (f *OptionalFlag) Times(target *[]time.Time, layouts ...string) *Times {
+	*target = []time.Time{}
+	value := &Times{target: target, envvar: f.f.env, optional: true}
+	f.f.value = &timesValue{key: f.key(), inner: value, config: f.f.config, layouts: layouts}
+	return value
+}
+
 func (f *OptionalFlag) StringMap(target *map[string]string) *StringMap {
-	value := &StringMap{target, f.f.env, nil, true}
+	value := &StringMap{target: target, envvar: f.f.env, optional: true}
 	f.f.value = &stringMapValue{key: f.key(), inner: value}
 	return value
 }
 
 func (f *OptionalFlag) Enum(target **string, possibilities ...string) *OptionalEnum {
-	value := &OptionalEnum{target, f.f.env, nil}
+	value := &OptionalEnum{target: target, envvar: f.f.env}
 	f.f.value = &optionalEnumValue{key: f.key(), inner: value, possibilities: possibilities}
 	return value
 }
 
-func verifyFlags(flags []*Flag) error {
+func verifyFlags(flags []*Flag, p *prompter) error {
 	for _, flag := range flags {
 		if err := flag.verify(flag.name); err != nil {
-			return err
+			// Deprecated flags are never required
+			var missing *missingInputError
+			if flag.deprecated != nil && errors.As(err, &missing) {
+				continue
+			}
+			if err := p.ask(err, flag.key(), flag.help, flag.value, false, flag.secret); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -8,14 +8,32 @@ import (
 
 type Float32 struct {
 	target *float32
-	envvar *string
+	envvar *env
 	defval *float32
+	source Source // where the value came from
 }
 
 func (v *Float32) Default(value float32) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Float32) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Float32) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Float32) IsSet() bool {
+	return v.source.isSet()
+}
+
 type float32Value struct {
 	key   string
 	inner *Float32
@@ -28,6 +46,19 @@ func (v *float32Value) optional() bool {
 	return false
 }
 
+func (v *float32Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *float32Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *float32Value) placeholder() string {
+	return "float"
+}
+
 func (v *float32Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -42,9 +73,13 @@ func (v *float32Value) Default() (string, bool) {
 func (v *float32Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -74,14 +109,32 @@ func (v *float32Value) String() string {
 
 type OptionalFloat32 struct {
 	target **float32
-	envvar *string
+	envvar *env
 	defval *float32
+	source Source // where the value came from
 }
 
 func (v *OptionalFloat32) Default(value float32) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalFloat32) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalFloat32) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalFloat32) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalFloat32Value struct {
 	key   string
 	inner *OptionalFloat32
@@ -94,6 +147,22 @@ func (v *optionalFloat32Value) optional() bool {
 	return true
 }
 
+func (v *optionalFloat32Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalFloat32Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalFloat32Value) placeholder() string {
+	return "float"
+}
+
 func (v *optionalFloat32Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -108,9 +177,13 @@ func (v *optionalFloat32Value) Default() (string, bool) {
 func (v *optionalFloat32Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -141,15 +214,33 @@ func (v *optionalFloat32Value) String() string {
 
 type Float32s struct {
 	target   *[]float32
-	envvar   *string
+	envvar   *env
 	defval   *[]float32
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Float32s) Default(values ...float32) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Float32s) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Float32s) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Float32s) IsSet() bool {
+	return v.source.isSet()
+}
+
 type float32sValue struct {
 	key   string
 	inner *Float32s
@@ -162,6 +253,22 @@ func (v *float32sValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *float32sValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *float32sValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *float32sValue) placeholder() string {
+	return "float"
+}
+
 func (v *float32sValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -183,9 +290,13 @@ func (v *float32sValue) Default() (string, bool) {
 func (v *float32sValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
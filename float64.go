@@ -8,14 +8,32 @@ import (
 
 type Float64 struct {
 	target *float64
-	envvar *string
+	envvar *env
 	defval *float64
+	source Source // where the value came from
 }
 
 func (v *Float64) Default(value float64) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Float64) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Float64) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Float64) IsSet() bool {
+	return v.source.isSet()
+}
+
 type float64Value struct {
 	key   string
 	inner *Float64
@@ -28,6 +46,19 @@ func (v *float64Value) optional() bool {
 	return false
 }
 
+func (v *float64Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *float64Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *float64Value) placeholder() string {
+	return "float"
+}
+
 func (v *float64Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -42,9 +73,13 @@ func (v *float64Value) Default() (string, bool) {
 func (v *float64Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -74,14 +109,32 @@ func (v *float64Value) String() string {
 
 type OptionalFloat64 struct {
 	target **float64
-	envvar *string
+	envvar *env
 	defval *float64
+	source Source // where the value came from
 }
 
 func (v *OptionalFloat64) Default(value float64) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalFloat64) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalFloat64) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalFloat64) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalFloat64Value struct {
 	key   string
 	inner *OptionalFloat64
@@ -94,6 +147,22 @@ func (v *optionalFloat64Value) optional() bool {
 	return true
 }
 
+func (v *optionalFloat64Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalFloat64Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalFloat64Value) placeholder() string {
+	return "float"
+}
+
 func (v *optionalFloat64Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -108,9 +177,13 @@ func (v *optionalFloat64Value) Default() (string, bool) {
 func (v *optionalFloat64Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -140,15 +213,33 @@ func (v *optionalFloat64Value) String() string {
 
 type Float64s struct {
 	target   *[]float64
-	envvar   *string
+	envvar   *env
 	defval   *[]float64
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Float64s) Default(values ...float64) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Float64s) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Float64s) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Float64s) IsSet() bool {
+	return v.source.isSet()
+}
+
 type float64sValue struct {
 	key   string
 	inner *Float64s
@@ -161,6 +252,22 @@ func (v *float64sValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *float64sValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *float64sValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *float64sValue) placeholder() string {
+	return "float"
+}
+
 func (v *float64sValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -182,9 +289,13 @@ func (v *float64sValue) Default() (string, bool) {
 func (v *float64sValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
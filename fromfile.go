@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FromFile allows the flag's value to be read from a file, which is how
+// container platforms mount secrets. The file can be passed with
+// --name-file <path>, as an --name=@path value or with the $NAME_FILE variant
+// of the flag's environment variable. The trailing newline is trimmed. Values
+// that start with @@ are passed as-is without the first @.
+func (f *Flag) FromFile() *Flag {
+	f.env.file = true
+	return f
+}
+
+// EnvFiles checks the $NAME_FILE variant of every environment variable and
+// reads the value from the file it points to.
+func (c *CLI) EnvFiles() *CLI {
+	c.config.envFiles = true
+	return c
+}
+
+// fileKey is the name of the flag that reads the value from a file
+func (f *Flag) fileKey() string {
+	return f.name + "-file"
+}
+
+// readFile reads the value from a file, trimming the trailing newline
+func readFile(key, subject, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: unable to read %s: %w", key, subject, err)
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+// isAtFile is true for @path values, but not for @@ escaped values
+func isAtFile(value string) bool {
+	return len(value) > 1 && value[0] == '@' && value[1] != '@'
+}
+
+// readAtFile reads the file of an @path value, unescapes @@ values and returns
+// other values as-is
+func readAtFile(key, value string) (string, error) {
+	if strings.HasPrefix(value, "@@") {
+		return value[1:], nil
+	} else if !isAtFile(value) {
+		return value, nil
+	}
+	return readFile(key, strconv.Quote(value), value[1:])
+}
+
+// atFileValue reads @path values on the command line from the file
+type atFileValue struct {
+	flag.Value
+	key string
+}
+
+func (v *atFileValue) Set(value string) error {
+	value, err := readAtFile(v.key, value)
+	if err != nil {
+		return err
+	}
+	return v.Value.Set(value)
+}
+
+// IsBoolFlag allows boolean flags to be passed without a value
+func (v *atFileValue) IsBoolFlag() bool {
+	return isBoolFlag(v.Value)
+}
+
+// fileValue sets the flag from the contents of a file
+type fileValue struct {
+	flag.Value
+	f *Flag
+}
+
+func (v *fileValue) Set(path string) error {
+	value, err := readFile(v.f.key(), "--"+v.f.fileKey(), path)
+	if err != nil {
+		return err
+	} else if err := v.Value.Set(value); err != nil {
+		if v.f.secret {
+			return secretError(v.f.key())
+		}
+		return err
+	}
+	return nil
+}
+
+// isFileError is true when the file couldn't be read
+func isFileError(err error) bool {
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr)
+}
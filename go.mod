@@ -8,6 +8,7 @@ require (
 	github.com/matryer/is v1.4.1
 	github.com/matthewmueller/diff v0.0.3
 	github.com/matthewmueller/testchild v0.0.1
+	golang.org/x/term v0.27.0
 )
 
 require (
@@ -17,6 +18,7 @@ require (
 	github.com/sergi/go-diff v1.3.1 // indirect
 	golang.org/x/mod v0.22.0 // indirect
 	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/tools v0.29.0 // indirect
 	mvdan.cc/gofumpt v0.7.0 // indirect
 )
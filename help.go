@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HelpCommand adds a "help [<command>...]" command to the root command that
+// prints the usage of the given command.
+func (c *CLI) HelpCommand() *CLI {
+	if _, ok := c.root.commands["help"]; ok {
+		panic(fmt.Sprintf("cli: cannot add a help command to %q because it already exists", c.root.full))
+	}
+	var path []string
+	// Don't inherit the root flags, since they might be required
+	help := newCommand(c.config, c.root, []*Flag{}, "help", c.root.full+" help", "show help for a command")
+	// Showing help shouldn't run the app's middleware
+	help.isolated = true
+	c.root.commands["help"] = help
+	help.Args("command", "command to show help for").Optional().Strings(&path)
+	help.Run(func(ctx context.Context) error {
+		defer func() { path = []string{} }()
+		cmd, err := c.findHelp(path...)
+		if err != nil {
+			return err
+		}
+		return cmd.printVerboseUsage()
+	})
+	c.help = true
+	return c
+}
+
+// findHelp finds the command, suggesting similar commands when not found
+func (c *CLI) findHelp(path ...string) (*command, error) {
+	cmd := c.root
+	for i, name := range path {
+		sub, ok := cmd.commands[name]
+		if !ok {
+			return nil, &commandNotFoundError{
+				Path:        path[:i+1],
+				Suggestions: suggest(cmd, name),
+			}
+		}
+		cmd = sub
+	}
+	return cmd, nil
+}
+
+type commandNotFoundError struct {
+	Path        []string
+	Suggestions []string
+}
+
+func (e *commandNotFoundError) Error() string {
+	s := new(strings.Builder)
+	s.WriteString(ErrCommandNotFound.Error())
+	s.WriteString(": ")
+	s.WriteString(strings.Join(e.Path, " "))
+	if len(e.Suggestions) == 0 {
+		return s.String()
+	}
+	s.WriteString(". Did you mean ")
+	ls := len(e.Suggestions)
+	for i, suggestion := range e.Suggestions {
+		if i > 0 && i == ls-1 {
+			s.WriteString(" or ")
+		} else if i > 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString(strconv.Quote(suggestion))
+	}
+	s.WriteString("?")
+	return s.String()
+}
+
+func (e *commandNotFoundError) Unwrap() error {
+	return ErrCommandNotFound
+}
+
+// suggest returns the visible subcommands (including aliases) that are
+// similar to name
+func suggest(cmd *command, name string) (suggestions []string) {
+	for key, sub := range cmd.commands {
+		if sub.hidden {
+			continue
+		}
+		if strings.HasPrefix(key, name) || levenshtein(key, name) <= max(2, len(name)/3) {
+			suggestions = append(suggestions, key)
+		}
+	}
+	sort.Strings(suggestions)
+	return suggestions
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"errors"
+	"sort"
+)
+
+// SkipCommand can be returned from a WalkFunc to skip the command's
+// subcommands.
+var SkipCommand = errors.New("cli: skip command")
+
+// WalkFunc is called for every command in the tree. The path is relative to the
+// root command, so the root command has an empty path.
+type WalkFunc = func(path []string, cmd CommandInfo) error
+
+// CommandInfo is a read-only view of a command
+type CommandInfo interface {
+	Name() string
+	Full() string
+	Help() string
+	Long() string
+	Aliases() []string
+	IsHidden() bool
+	IsAdvanced() bool
+	Deprecated() (string, bool)
+	Runnable() bool
+	Flags() []FlagInfo
+	FlagGroups() []string
+	Args() []ArgInfo
+	RestArgs() ArgInfo
+	Commands() []CommandInfo
+	Examples() []ExampleInfo
+}
+
+// ExampleInfo is a read-only view of an example
+type ExampleInfo interface {
+	Command() string
+	Help() string
+}
+
+// FlagInfo is a read-only view of a flag
+type FlagInfo interface {
+	Name() string
+	Short() string
+	Aliases() []string
+	Help() string
+	Long() string
+	Group() string
+	IsHidden() bool
+	IsAdvanced() bool
+	IsSecret() bool
+	Placeholder() string
+	Env() (string, bool)
+	Default() (string, bool)
+	Optional() bool
+	Deprecated() (string, bool)
+	DeprecatedNames() []string
+}
+
+// ArgInfo is a read-only view of an argument
+type ArgInfo interface {
+	Name() string
+	Help() string
+	IsHidden() bool
+	Env() (string, bool)
+	Default() (string, bool)
+	Optional() bool
+	Variadic() bool
+	Deprecated() (string, bool)
+}
+
+// Walk the command tree depth-first, starting at the root command. Subcommands
+// are visited in alphabetical order and aliases are only visited once.
+func (c *CLI) Walk(fn WalkFunc) error {
+	err := walk([]string{}, c.root, fn)
+	if errors.Is(err, SkipCommand) {
+		return nil
+	}
+	return err
+}
+
+func walk(path []string, cmd *command, fn WalkFunc) error {
+	if err := fn(path, &commandInfo{cmd}); err != nil {
+		return err
+	}
+	for _, sub := range sortedCommands(cmd) {
+		err := walk(append(path[:len(path):len(path)], sub.name), sub, fn)
+		if err != nil && !errors.Is(err, SkipCommand) {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedCommands returns the unique subcommands sorted by name
+func sortedCommands(cmd *command) (commands []*command) {
+	seen := map[*command]bool{}
+	for _, sub := range cmd.commands {
+		if seen[sub] {
+			continue
+		}
+		seen[sub] = true
+		commands = append(commands, sub)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].name < commands[j].name
+	})
+	return commands
+}
+
+type commandInfo struct {
+	c *command
+}
+
+var _ CommandInfo = (*commandInfo)(nil)
+
+func (i *commandInfo) Name() string {
+	return i.c.name
+}
+
+func (i *commandInfo) Full() string {
+	return i.c.full
+}
+
+func (i *commandInfo) Help() string {
+	return i.c.help
+}
+
+func (i *commandInfo) Long() string {
+	return i.c.long
+}
+
+func (i *commandInfo) Aliases() []string {
+	return i.c.aliases
+}
+
+func (i *commandInfo) IsHidden() bool {
+	return i.c.hidden
+}
+
+func (i *commandInfo) IsAdvanced() bool {
+	return i.c.advanced
+}
+
+func (i *commandInfo) Deprecated() (string, bool) {
+	return deprecatedInfo(i.c.deprecated)
+}
+
+func (i *commandInfo) Runnable() bool {
+	return i.c.run != nil
+}
+
+func (i *commandInfo) Flags() []FlagInfo {
+	flags := make([]FlagInfo, len(i.c.flags))
+	for j, flag := range i.c.flags {
+		flags[j] = &flagInfo{flag}
+	}
+	return flags
+}
+
+func (i *commandInfo) FlagGroups() []string {
+	return i.c.flagGroups()
+}
+
+func (i *commandInfo) Args() []ArgInfo {
+	args := make([]ArgInfo, len(i.c.args))
+	for j, arg := range i.c.args {
+		args[j] = &argInfo{arg.name, arg.help, arg.env, arg.value, false, arg.hidden, arg.deprecated}
+	}
+	return args
+}
+
+func (i *commandInfo) RestArgs() ArgInfo {
+	if i.c.restArgs == nil {
+		return nil
+	}
+	args := i.c.restArgs
+	return &argInfo{args.name, args.help, args.env, args.value, true, false, nil}
+}
+
+func (i *commandInfo) Commands() []CommandInfo {
+	subs := sortedCommands(i.c)
+	commands := make([]CommandInfo, len(subs))
+	for j, sub := range subs {
+		commands[j] = &commandInfo{sub}
+	}
+	return commands
+}
+
+func (i *commandInfo) Examples() []ExampleInfo {
+	examples := make([]ExampleInfo, len(i.c.examples))
+	for j, example := range i.c.examples {
+		examples[j] = &exampleInfo{i.c, example}
+	}
+	return examples
+}
+
+type exampleInfo struct {
+	c *command
+	e *example
+}
+
+var _ ExampleInfo = (*exampleInfo)(nil)
+
+func (i *exampleInfo) Command() string {
+	return joinCommand(i.c.full, i.e.cmdline)
+}
+
+func (i *exampleInfo) Help() string {
+	return i.e.help
+}
+
+type flagInfo struct {
+	f *Flag
+}
+
+var _ FlagInfo = (*flagInfo)(nil)
+
+func (i *flagInfo) Name() string {
+	return i.f.name
+}
+
+func (i *flagInfo) Short() string {
+	return i.f.short
+}
+
+func (i *flagInfo) Aliases() []string {
+	return i.f.aliases
+}
+
+func (i *flagInfo) Help() string {
+	return i.f.help
+}
+
+func (i *flagInfo) Long() string {
+	return i.f.long
+}
+
+func (i *flagInfo) Group() string {
+	return i.f.group
+}
+
+func (i *flagInfo) IsHidden() bool {
+	return i.f.hidden
+}
+
+func (i *flagInfo) IsAdvanced() bool {
+	return i.f.advanced
+}
+
+func (i *flagInfo) IsSecret() bool {
+	return i.f.secret
+}
+
+func (i *flagInfo) Placeholder() string {
+	return (&usageFlag{f: i.f}).Placeholder()
+}
+
+func (i *flagInfo) Env() (string, bool) {
+	if !hasEnv(i.f.env) {
+		return "", false
+	}
+	return i.f.env.name, true
+}
+
+func (i *flagInfo) Default() (string, bool) {
+	if i.f.value == nil {
+		return "", false
+	}
+	def, ok := i.f.value.Default()
+	return redact(def, i.f.secret), ok
+}
+
+func (i *flagInfo) Optional() bool {
+	if i.f.value == nil {
+		return false
+	}
+	return i.f.value.optional()
+}
+
+func (i *flagInfo) Deprecated() (string, bool) {
+	return deprecatedInfo(i.f.deprecated)
+}
+
+func (i *flagInfo) DeprecatedNames() []string {
+	return i.f.oldNames
+}
+
+type argInfo struct {
+	name       string
+	help       string
+	env        *env
+	value      value
+	variadic   bool
+	hidden     bool
+	deprecated *string
+}
+
+var _ ArgInfo = (*argInfo)(nil)
+
+func (i *argInfo) Name() string {
+	return i.name
+}
+
+func (i *argInfo) Help() string {
+	return i.help
+}
+
+func (i *argInfo) IsHidden() bool {
+	return i.hidden
+}
+
+func (i *argInfo) Env() (string, bool) {
+	if !hasEnv(i.env) {
+		return "", false
+	}
+	return i.env.name, true
+}
+
+func (i *argInfo) Default() (string, bool) {
+	if i.value == nil {
+		return "", false
+	}
+	return i.value.Default()
+}
+
+func (i *argInfo) Optional() bool {
+	if i.value == nil {
+		return false
+	}
+	return i.value.optional()
+}
+
+func (i *argInfo) Variadic() bool {
+	return i.variadic
+}
+
+func (i *argInfo) Deprecated() (string, bool) {
+	return deprecatedInfo(i.deprecated)
+}
+
+func deprecatedInfo(msg *string) (string, bool) {
+	if msg == nil {
+		return "", false
+	}
+	return *msg, true
+}
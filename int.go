@@ -7,14 +7,32 @@ import (
 
 type Int struct {
 	target *int
-	envvar *string
+	envvar *env
 	defval *int
+	source Source // where the value came from
 }
 
 func (v *Int) Default(value int) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Int) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Int) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Int) IsSet() bool {
+	return v.source.isSet()
+}
+
 type intValue struct {
 	key   string
 	inner *Int
@@ -27,6 +45,19 @@ func (v *intValue) optional() bool {
 	return false
 }
 
+func (v *intValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *intValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *intValue) placeholder() string {
+	return "int"
+}
+
 func (v *intValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -41,9 +72,13 @@ func (v *intValue) Default() (string, bool) {
 func (v *intValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -73,14 +108,32 @@ func (v *intValue) String() string {
 
 type OptionalInt struct {
 	target **int
-	envvar *string
+	envvar *env
 	defval *int
+	source Source // where the value came from
 }
 
 func (v *OptionalInt) Default(value int) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalInt) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalInt) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalInt) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalIntValue struct {
 	key   string
 	inner *OptionalInt
@@ -93,6 +146,22 @@ func (v *optionalIntValue) optional() bool {
 	return true
 }
 
+func (v *optionalIntValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalIntValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalIntValue) placeholder() string {
+	return "int"
+}
+
 func (v *optionalIntValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -107,9 +176,13 @@ func (v *optionalIntValue) Default() (string, bool) {
 func (v *optionalIntValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
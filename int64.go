@@ -8,14 +8,32 @@ import (
 
 type Int64 struct {
 	target *int64
-	envvar *string
+	envvar *env
 	defval *int64
+	source Source // where the value came from
 }
 
 func (v *Int64) Default(value int64) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Int64) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Int64) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Int64) IsSet() bool {
+	return v.source.isSet()
+}
+
 type int64Value struct {
 	key   string
 	inner *Int64
@@ -28,6 +46,19 @@ func (v *int64Value) optional() bool {
 	return false
 }
 
+func (v *int64Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *int64Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *int64Value) placeholder() string {
+	return "int"
+}
+
 func (v *int64Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -42,9 +73,13 @@ func (v *int64Value) Default() (string, bool) {
 func (v *int64Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -74,14 +109,32 @@ func (v *int64Value) String() string {
 
 type OptionalInt64 struct {
 	target **int64
-	envvar *string
+	envvar *env
 	defval *int64
+	source Source // where the value came from
 }
 
 func (v *OptionalInt64) Default(value int64) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalInt64) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalInt64) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalInt64) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalInt64Value struct {
 	key   string
 	inner *OptionalInt64
@@ -94,6 +147,22 @@ func (v *optionalInt64Value) optional() bool {
 	return true
 }
 
+func (v *optionalInt64Value) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalInt64Value) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalInt64Value) placeholder() string {
+	return "int"
+}
+
 func (v *optionalInt64Value) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -108,9 +177,13 @@ func (v *optionalInt64Value) Default() (string, bool) {
 func (v *optionalInt64Value) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -140,15 +213,33 @@ func (v *optionalInt64Value) String() string {
 
 type Int64s struct {
 	target   *[]int64
-	envvar   *string
+	envvar   *env
 	defval   *[]int64
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Int64s) Default(values ...int64) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Int64s) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Int64s) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Int64s) IsSet() bool {
+	return v.source.isSet()
+}
+
 type int64sValue struct {
 	key   string
 	inner *Int64s
@@ -161,6 +252,22 @@ func (v *int64sValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *int64sValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *int64sValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *int64sValue) placeholder() string {
+	return "int"
+}
+
 func (v *int64sValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -182,9 +289,13 @@ func (v *int64sValue) Default() (string, bool) {
 func (v *int64sValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"strings"
+)
+
+// Invocation describes the command that's running. It's available to
+// middleware and runners with InvocationFrom.
+type Invocation struct {
+	Command string   // the full command, e.g. "heroku ps scale"
+	Path    []string // the command names after the root, e.g. ["ps", "scale"]
+	Aliases []string // the aliases that were used to select the command
+	Flags   []*Input // the flags with their resolved values
+	Args    []*Input // the arguments with their resolved values
+	Argv    []string // the arguments passed to Parse with secrets redacted
+}
+
+// Input is a flag or an argument with its resolved value. Secret values are
+// replaced with "***".
+type Input struct {
+	Name   string
+	Value  string
+	Source Source
+}
+
+type invocationKey struct{}
+
+// InvocationFrom returns the invocation from the context or nil if the context
+// didn't come from Parse.
+func InvocationFrom(ctx context.Context) *Invocation {
+	invocation, _ := ctx.Value(invocationKey{}).(*Invocation)
+	return invocation
+}
+
+func withInvocation(ctx context.Context, argv []string) context.Context {
+	return context.WithValue(ctx, invocationKey{}, &Invocation{Argv: argv})
+}
+
+// selectCommand records the subcommand that was selected by name
+func (i *Invocation) selectCommand(name string, sub *command) {
+	i.Path = append(i.Path, sub.name)
+	if name != sub.name {
+		i.Aliases = append(i.Aliases, name)
+	}
+}
+
+// resolve records the command's resolved flags and arguments
+func (i *Invocation) resolve(c *command) {
+	i.Command = c.full
+	i.Argv = redactArgv(i.Argv, secretFlags(c))
+	for _, flag := range c.flags {
+		input := newInput(flag.name, flag.value)
+		input.Value = redact(input.Value, flag.secret)
+		i.Flags = append(i.Flags, input)
+	}
+	for _, arg := range c.args {
+		i.Args = append(i.Args, newInput(arg.name, arg.value))
+	}
+	if c.restArgs != nil {
+		i.Args = append(i.Args, newInput(c.restArgs.name, c.restArgs.value))
+	}
+}
+
+func newInput(name string, value value) *Input {
+	return &Input{name, value.String(), *value.source()}
+}
+
+// secretFlags returns the secret flags by every name they can be passed with
+func secretFlags(c *command) map[string]*Flag {
+	secrets := map[string]*Flag{}
+	for cmd := c; cmd != nil; cmd = cmd.parent {
+		for _, flag := range cmd.flags {
+			if !flag.secret {
+				continue
+			}
+			names := append([]string{flag.name, flag.short}, flag.aliases...)
+			names = append(names, flag.oldNames...)
+			if flag.env.file {
+				names = append(names, flag.fileKey())
+			}
+			for _, name := range names {
+				if name != "" {
+					secrets[name] = flag
+				}
+			}
+		}
+	}
+	return secrets
+}
+
+// redactArgv returns a copy of the arguments with the values of the secret
+// flags replaced
+func redactArgv(argv []string, secrets map[string]*Flag) []string {
+	out := make([]string, len(argv))
+	copy(out, argv)
+	for i := 0; i < len(out); i++ {
+		arg := out[i]
+		if arg == "--" {
+			break
+		} else if !isFlag(arg) {
+			continue
+		}
+		key, _, hasValue := strings.Cut(arg, "=")
+		name := strings.TrimLeft(key, "-")
+		flag, ok := secrets[name]
+		if !ok {
+			continue
+		}
+		if hasValue {
+			out[i] = key + "=" + redacted
+			continue
+		}
+		// Boolean flags don't take the next argument
+		if name != flag.fileKey() && isBoolFlag(flag.value) {
+			continue
+		}
+		if i+1 < len(out) {
+			out[i+1] = redacted
+			i++
+		}
+	}
+	return out
+}
@@ -0,0 +1,347 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// pathKind is the check that's done on a path
+type pathKind uint8
+
+const (
+	anyPath pathKind = iota
+	dirPath
+	filePath
+)
+
+func (k pathKind) placeholder() string {
+	switch k {
+	case dirPath:
+		return "dir"
+	case filePath:
+		return "file"
+	default:
+		return "path"
+	}
+}
+
+// expandPath expands a leading "~" to the home directory
+func expandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~`+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// checkPath expands the path and checks that it exists when required
+func checkPath(key string, kind pathKind, path string) (string, error) {
+	expanded, err := expandPath(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: unable to expand %q: %w", key, path, err)
+	}
+	if kind == anyPath {
+		return expanded, nil
+	}
+	info, err := os.Stat(expanded)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%s: %q does not exist", key, path)
+		}
+		return "", fmt.Errorf("%s: unable to stat %q: %w", key, path, unwrapPathError(err))
+	}
+	if kind == dirPath && !info.IsDir() {
+		return "", fmt.Errorf("%s: %q is not a directory", key, path)
+	} else if kind == filePath && info.IsDir() {
+		return "", fmt.Errorf("%s: %q is a directory", key, path)
+	}
+	return expanded, nil
+}
+
+// unwrapPathError drops the operation and path from the error, since the path
+// is already in the message
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
+
+type Path struct {
+	target *string
+	envvar *env
+	defval *string // default value
+	source Source  // where the value came from
+}
+
+func (v *Path) Default(value string) {
+	v.defval = &value
+}
+
+// Source returns where the value came from
+func (v *Path) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Path) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Path) IsSet() bool {
+	return v.source.isSet()
+}
+
+type pathValue struct {
+	key   string
+	kind  pathKind
+	inner *Path
+	set   bool
+}
+
+var _ value = (*pathValue)(nil)
+
+func (v *pathValue) optional() bool {
+	return false
+}
+
+func (v *pathValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *pathValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = ""
+	}
+}
+
+func (v *pathValue) placeholder() string {
+	return v.kind.placeholder()
+}
+
+func (v *pathValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		path, err := checkPath(v.key, v.kind, *v.inner.defval)
+		if err != nil {
+			return err
+		}
+		*v.inner.target = path
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *pathValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *pathValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	return *v.inner.defval, true
+}
+
+func (v *pathValue) Set(val string) error {
+	path, err := checkPath(v.key, v.kind, val)
+	if err != nil {
+		return err
+	}
+	*v.inner.target = path
+	v.set = true
+	return nil
+}
+
+func (v *pathValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return *v.inner.target
+	} else if v.hasDefault() {
+		return *v.inner.defval
+	}
+	return ""
+}
+
+type Paths struct {
+	target   *[]string
+	envvar   *env
+	defval   *[]string
+	optional bool
+	glob     bool
+	source   Source // where the value came from
+}
+
+func (v *Paths) Default(values ...string) {
+	v.defval = &values
+}
+
+// Glob expands patterns like "*.go" into the matching paths. Patterns that
+// don't match anything are an error.
+func (v *Paths) Glob() *Paths {
+	v.glob = true
+	return v
+}
+
+// Source returns where the value came from
+func (v *Paths) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Paths) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Paths) IsSet() bool {
+	return v.source.isSet()
+}
+
+type pathsValue struct {
+	key   string
+	kind  pathKind
+	inner *Paths
+	set   bool
+}
+
+var _ value = (*pathsValue)(nil)
+
+func (v *pathsValue) optional() bool {
+	return v.inner.optional
+}
+
+func (v *pathsValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *pathsValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *pathsValue) placeholder() string {
+	return v.kind.placeholder()
+}
+
+func (v *pathsValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		fields, err := shellquote.Split(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a list of paths but got %q", v.key, value)
+		}
+		for _, val := range fields {
+			if err := v.Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		for _, val := range *v.inner.defval {
+			if err := v.Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if v.inner.optional {
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *pathsValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *pathsValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	if len(*v.inner.defval) == 0 {
+		return "[]", true
+	}
+	return strings.Join(*v.inner.defval, ", "), true
+}
+
+func (v *pathsValue) Set(val string) error {
+	paths := []string{val}
+	if v.inner.glob {
+		matches, err := v.expandGlob(val)
+		if err != nil {
+			return err
+		}
+		paths = matches
+	}
+	for _, path := range paths {
+		path, err := checkPath(v.key, v.kind, path)
+		if err != nil {
+			return err
+		}
+		*v.inner.target = append(*v.inner.target, path)
+	}
+	v.set = true
+	return nil
+}
+
+func (v *pathsValue) expandGlob(pattern string) ([]string, error) {
+	expanded, err := expandPath(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to expand %q: %w", v.key, pattern, err)
+	}
+	// Paths without glob characters are passed through as-is
+	if !strings.ContainsAny(expanded, `*?[`) {
+		return []string{pattern}, nil
+	}
+	matches, err := filepath.Glob(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid pattern %q: %w", v.key, pattern, err)
+	} else if len(matches) == 0 {
+		return nil, fmt.Errorf("%s: no paths match %q", v.key, pattern)
+	}
+	return matches, nil
+}
+
+func (v *pathsValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return strings.Join(*v.inner.target, ", ")
+	} else if v.hasDefault() {
+		return strings.Join(*v.inner.defval, ", ")
+	}
+	return ""
+}
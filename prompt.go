@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"golang.org/x/term"
+)
+
+// Interactive prompts for missing required flags and arguments instead of
+// returning an error. Prompting only happens when stdin is a terminal, so CI
+// and piped input keep failing on missing values.
+func (c *CLI) Interactive() *CLI {
+	c.config.interactive = true
+	return c
+}
+
+// isTerminal is true when the reader is a terminal. Readers can also report
+// whether they're a terminal with an IsTerminal method, which is useful in
+// tests.
+func isTerminal(r io.Reader) bool {
+	if t, ok := r.(interface{ IsTerminal() bool }); ok {
+		return t.IsTerminal()
+	}
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// prompter returns nil when the CLI shouldn't prompt
+func (c *config) prompter(ctx context.Context) *prompter {
+	if !c.interactive || isDryRun(ctx) || !isTerminal(c.stdin) {
+		return nil
+	}
+	return &prompter{c.stdin, c.stderr, c.errorStyle()}
+}
+
+// prompter asks for missing values
+type prompter struct {
+	in    io.Reader
+	out   io.Writer
+	error Style // styles the errors when the input is invalid
+}
+
+// choices are the values that can be selected when prompted
+type choices interface {
+	choices() []string
+}
+
+// ask for the value when verify returns a missingInputError
+func (p *prompter) ask(err error, key, help string, v value, variadic, secret bool) error {
+	var missing *missingInputError
+	if p == nil || !errors.As(err, &missing) {
+		return err
+	}
+	question := key
+	if help != "" {
+		question = help + " (" + key + ")"
+	}
+	options := []string{}
+	if c, ok := v.(choices); ok {
+		options = c.choices()
+	}
+	for {
+		if len(options) > 0 {
+			fmt.Fprintln(p.out, question)
+			for i, option := range options {
+				fmt.Fprintf(p.out, "  %d) %s\n", i+1, option)
+			}
+			fmt.Fprintf(p.out, "Choose 1-%d: ", len(options))
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, ok := p.read(secret)
+		if !ok {
+			// Stop prompting when the input is closed
+			fmt.Fprintln(p.out)
+			return err
+		} else if line == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(line); err == nil && n > 0 && n <= len(options) {
+			line = options[n-1]
+		}
+		if err := p.set(v, line, variadic); err != nil {
+			if secret {
+				err = secretError(key)
+			}
+			fmt.Fprintln(p.out, paint(true, p.error, err.Error()))
+			continue
+		}
+		*v.source() = Source{Kind: SourcePrompt}
+		return nil
+	}
+}
+
+func (p *prompter) set(v value, line string, variadic bool) error {
+	if !variadic {
+		return v.Set(line)
+	}
+	fields, err := shellquote.Split(line)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := v.Set(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read a line, without echoing it back when it's a secret
+func (p *prompter) read(secret bool) (string, bool) {
+	f, ok := p.in.(*os.File)
+	if !secret || !ok || !term.IsTerminal(int(f.Fd())) {
+		return readLine(p.in)
+	}
+	line, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(p.out)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(line)), true
+}
+
+// readLine reads a byte at a time, so the rest of the input is left for the
+// next prompt or the command
+func readLine(r io.Reader) (string, bool) {
+	line := new(strings.Builder)
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSpace(line.String()), true
+			}
+			line.WriteByte(b[0])
+		}
+		if err != nil {
+			return strings.TrimSpace(line.String()), line.Len() > 0
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// redacted replaces secret values in the help, info, invocation and errors
+const redacted = "***"
+
+// Secret hides the flag's value. Secrets are never shown in the help, info,
+// invocation or error messages, are read without echo when prompted, and warn
+// when passed on the command line, where they end up in the shell history.
+func (f *Flag) Secret() *Flag {
+	f.secret = true
+	return f
+}
+
+// secretValue warns when the secret is passed on the command line and hides
+// the value in errors
+type secretValue struct {
+	flag.Value
+	config *config
+	f      *Flag
+}
+
+func (v *secretValue) Set(value string) error {
+	if v.f.env.file && isAtFile(value) {
+		// The secret is read from a file, so it's not in the shell history
+	} else if hasEnv(v.f.env) {
+		v.config.warn(v.f.key() + " is a secret, use " + v.f.env.String() + " to keep it out of your shell history")
+	} else {
+		v.config.warn(v.f.key() + " is a secret, passing it on the command line leaves it in your shell history")
+	}
+	if err := v.Value.Set(value); err != nil {
+		if isFileError(err) {
+			return err
+		}
+		return secretError(v.f.key())
+	}
+	return nil
+}
+
+// IsBoolFlag allows secret boolean flags to be passed without a value
+func (v *secretValue) IsBoolFlag() bool {
+	return isBoolFlag(v.Value)
+}
+
+// secretError replaces errors that may contain the secret
+func secretError(key string) error {
+	return fmt.Errorf("%s: invalid value %s", key, redacted)
+}
+
+// verifySecret hides the secret in errors from the environment or defaults
+func verifySecret(f *Flag, err error) error {
+	var missing *missingInputError
+	if err == nil || !f.secret || errors.As(err, &missing) || isFileError(err) {
+		return err
+	}
+	return secretError(f.key())
+}
+
+// redact the value when it's a secret
+func redact(value string, secret bool) string {
+	if secret && value != "" {
+		return redacted
+	}
+	return value
+}
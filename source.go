@@ -0,0 +1,79 @@
+package cli
+
+import "flag"
+
+// SourceKind is the kind of input that set a value
+type SourceKind int
+
+const (
+	// SourceUnset means the value wasn't set
+	SourceUnset SourceKind = iota
+	// SourceCLI means the value was passed on the command line
+	SourceCLI
+	// SourceEnv means the value came from an environment variable
+	SourceEnv
+	// SourceDefault means the value is the default
+	SourceDefault
+	// SourcePrompt means the value was entered when prompted
+	SourcePrompt
+	// SourceConfig means the value came from a config file, see String.Config
+	SourceConfig
+)
+
+func (k SourceKind) String() string {
+	switch k {
+	case SourceCLI:
+		return "cli"
+	case SourceEnv:
+		return "env"
+	case SourceDefault:
+		return "default"
+	case SourcePrompt:
+		return "prompt"
+	case SourceConfig:
+		return "config"
+	default:
+		return "unset"
+	}
+}
+
+// Source is where a value came from
+type Source struct {
+	Kind SourceKind
+	Env  string // the environment variable, when the kind is SourceEnv
+}
+
+func (s Source) String() string {
+	if s.Kind == SourceEnv {
+		return "env $" + s.Env
+	}
+	return s.Kind.String()
+}
+
+// isSet is true when the value was explicitly set, rather than defaulted
+func (s Source) isSet() bool {
+	return s.Kind == SourceCLI || s.Kind == SourceEnv || s.Kind == SourcePrompt || s.Kind == SourceConfig
+}
+
+// cliValue records that the value was passed on the command line
+type cliValue struct {
+	value
+}
+
+func (v *cliValue) Set(val string) error {
+	if err := v.value.Set(val); err != nil {
+		return err
+	}
+	*v.source() = Source{Kind: SourceCLI}
+	return nil
+}
+
+// IsBoolFlag allows boolean flags to be passed without a value
+func (v *cliValue) IsBoolFlag() bool {
+	return isBoolFlag(v.value)
+}
+
+func isBoolFlag(value flag.Value) bool {
+	b, ok := value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Stdin sets the reader that commands read from, defaults to os.Stdin
+func (c *CLI) Stdin(reader io.Reader) *CLI {
+	c.config.stdin = reader
+	return c
+}
+
+type configKey struct{}
+
+func withConfig(ctx context.Context, config *config) context.Context {
+	return context.WithValue(ctx, configKey{}, config)
+}
+
+// StdinFrom returns the CLI's stdin from the context, falling back to
+// os.Stdin when the context didn't come from Parse.
+func StdinFrom(ctx context.Context) io.Reader {
+	if config, ok := ctx.Value(configKey{}).(*config); ok {
+		return config.stdin
+	}
+	return os.Stdin
+}
+
+// StdoutFrom returns the CLI's stdout from the context, falling back to
+// os.Stdout when the context didn't come from Parse.
+func StdoutFrom(ctx context.Context) io.Writer {
+	if config, ok := ctx.Value(configKey{}).(*config); ok {
+		return config.stdout
+	}
+	return os.Stdout
+}
+
+// StderrFrom returns the CLI's stderr from the context, falling back to
+// os.Stderr when the context didn't come from Parse.
+func StderrFrom(ctx context.Context) io.Writer {
+	if config, ok := ctx.Value(configKey{}).(*config); ok {
+		return config.stderr
+	}
+	return os.Stderr
+}
@@ -2,14 +2,32 @@ package cli
 
 type String struct {
 	target *string
-	envvar *string
+	envvar *env
 	defval *string // default value
+	source Source  // where the value came from
 }
 
 func (v *String) Default(value string) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *String) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *String) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *String) IsSet() bool {
+	return v.source.isSet()
+}
+
 type stringValue struct {
 	key   string
 	inner *String
@@ -20,14 +38,31 @@ func (v *stringValue) optional() bool {
 	return false
 }
 
+func (v *stringValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *stringValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *stringValue) placeholder() string {
+	return "string"
+}
+
 var _ value = (*stringValue)(nil)
 
 func (v *stringValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -64,14 +99,32 @@ func (v *stringValue) String() string {
 
 type OptionalString struct {
 	target **string
-	envvar *string
+	envvar *env
 	defval *string // default value
+	source Source  // where the value came from
 }
 
 func (v *OptionalString) Default(value string) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalString) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalString) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalString) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalStringValue struct {
 	key   string
 	inner *OptionalString
@@ -84,6 +137,22 @@ func (v *optionalStringValue) optional() bool {
 	return true
 }
 
+func (v *optionalStringValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalStringValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalStringValue) placeholder() string {
+	return "string"
+}
+
 func (v *optionalStringValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -98,9 +167,13 @@ func (v *optionalStringValue) Default() (string, bool) {
 func (v *optionalStringValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -9,15 +9,33 @@ import (
 
 type StringMap struct {
 	target   *map[string]string
-	envvar   *string
+	envvar   *env
 	defval   *map[string]string // default value
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *StringMap) Default(value map[string]string) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *StringMap) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *StringMap) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *StringMap) IsSet() bool {
+	return v.source.isSet()
+}
+
 type stringMapValue struct {
 	key   string
 	inner *StringMap
@@ -30,10 +48,29 @@ func (v *stringMapValue) optional() bool {
 	return false
 }
 
+func (v *stringMapValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *stringMapValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil && *v.inner.target != nil {
+		*v.inner.target = map[string]string{}
+	}
+}
+
+func (v *stringMapValue) placeholder() string {
+	return "key:value"
+}
+
 func (v *stringMapValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		fields, err := shellquote.Split(value)
 		if err != nil {
 			return fmt.Errorf("%s: expected a string map but got %q", v.key, value)
@@ -45,6 +82,7 @@ func (v *stringMapValue) verify() error {
 		}
 		return nil
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
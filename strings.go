@@ -9,15 +9,33 @@ import (
 
 type Strings struct {
 	target   *[]string
-	envvar   *string
+	envvar   *env
 	defval   *[]string // default value
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Strings) Default(values ...string) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Strings) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Strings) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Strings) IsSet() bool {
+	return v.source.isSet()
+}
+
 type stringsValue struct {
 	key   string
 	inner *Strings
@@ -30,10 +48,29 @@ func (v *stringsValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *stringsValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *stringsValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *stringsValue) placeholder() string {
+	return "string"
+}
+
 func (v *stringsValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		fields, err := shellquote.Split(value)
 		if err != nil {
 			return fmt.Errorf("%s: expected a list of strings but got %q", v.key, value)
@@ -45,6 +82,7 @@ func (v *stringsValue) verify() error {
 		}
 		return nil
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Style is an ANSI escape sequence used to style the help output
+type Style string
+
+// Styles combines multiple styles into one, e.g. Styles(Bold, Underline)
+func Styles(styles ...Style) Style {
+	out := new(strings.Builder)
+	for _, style := range styles {
+		out.WriteString(string(style))
+	}
+	return Style(out.String())
+}
+
+// Color256 is a foreground color from the 256-color palette
+func Color256(n uint8) Style {
+	return Style(fmt.Sprintf("\033[38;5;%dm", n))
+}
+
+// TrueColor is a 24-bit foreground color
+func TrueColor(r, g, b uint8) Style {
+	return Style(fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b))
+}
+
+// Hex is a 24-bit foreground color in the form of "#rrggbb". It panics when
+// the color is invalid, because themes are set up during initialization.
+func Hex(hex string) Style {
+	digits := strings.TrimPrefix(hex, "#")
+	rgb, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) != 6 {
+		panic(fmt.Sprintf("cli: invalid hex color %q", hex))
+	}
+	return TrueColor(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb))
+}
+
+const (
+	Bold      Style = "\033[1m"
+	Dim       Style = "\033[2m"
+	Underline Style = "\033[4m"
+	Red       Style = "\033[31m"
+	Green     Style = "\033[32m"
+	Yellow    Style = "\033[33m"
+	Blue      Style = "\033[34m"
+	Teal      Style = "\033[36m"
+	Gray      Style = "\033[37m"
+)
+
+// Theme styles the different parts of the help output. Empty styles are left
+// unstyled.
+type Theme struct {
+	Heading     Style // section headings, e.g. "Flags:"
+	Hint        Style // the prompt and placeholders in the usage line
+	Command     Style // command names
+	Flag        Style // flag names
+	Arg         Style // argument names
+	Description Style // help text
+	Default     Style // defaults and other attributes after the help text
+	Error       Style // warnings and errors printed to stderr
+}
+
+// DefaultTheme is the theme used when no theme is set. It dims secondary text
+// instead of coloring it gray, so it stays readable on light terminals.
+var DefaultTheme = Theme{
+	Heading:     Bold,
+	Hint:        Dim,
+	Description: Dim,
+	Default:     Dim,
+	Error:       Red,
+}
+
+// Theme sets the styles used in the help output
+func (c *CLI) Theme(theme Theme) *CLI {
+	c.config.theme = theme
+	return c
+}
+
+// paint the text with the style, if colors are enabled
+func (u *usage) paint(style Style, text string) string {
+	return paint(u.color, style, text)
+}
+
+func paint(enabled bool, style Style, text string) string {
+	if !enabled || style == "" || text == "" {
+		return text
+	}
+	return string(style) + text + resetCode
+}
+
+// errorStyle styles warnings and errors printed to stderr
+func (c *config) errorStyle() Style {
+	if !c.colorEnabled(c.stderr) {
+		return ""
+	}
+	return c.theme.Error
+}
+
+// themeFuncs are the semantic template functions for the theme
+func themeFuncs(theme Theme, enabled bool) template.FuncMap {
+	return template.FuncMap{
+		"heading":     color(enabled, string(theme.Heading)),
+		"hint":        color(enabled, string(theme.Hint)),
+		"command":     color(enabled, string(theme.Command)),
+		"flag":        color(enabled, string(theme.Flag)),
+		"arg":         color(enabled, string(theme.Arg)),
+		"description": color(enabled, string(theme.Description)),
+		"default":     color(enabled, string(theme.Default)),
+		"error":       color(enabled, string(theme.Error)),
+	}
+}
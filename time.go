@@ -0,0 +1,464 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/kballard/go-shellquote"
+)
+
+// Clock sets the function that relative times like "2h" resolve against,
+// defaults to time.Now
+func (c *CLI) Clock(now func() time.Time) *CLI {
+	c.config.now = now
+	return c
+}
+
+// timeLayouts are tried after the custom layouts
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime parses "now", an absolute time in one of the layouts or a time
+// relative to now. Durations like "2h" or "-3d" are in the past and durations
+// like "+1w" are in the future.
+func parseTime(now time.Time, layouts []string, s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "now") {
+		return now, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if rest, ok := strings.CutPrefix(s, "+"); ok {
+		d, err := duration.Parse(rest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(d), nil
+	}
+	d, err := duration.Parse(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-d), nil
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// clock returns the time that relative times resolve against. The clock is
+// read once per parse, so relative times within a parse are consistent.
+func (c *config) clock() time.Time {
+	if !c.started.IsZero() {
+		return c.started
+	} else if c.now != nil {
+		c.started = c.now()
+	} else {
+		c.started = time.Now()
+	}
+	return c.started
+}
+
+// checkRelative panics when the relative default can't be parsed
+func checkRelative(value string) {
+	if _, err := parseTime(time.Time{}, nil, value); err != nil {
+		panic(fmt.Sprintf("cli: invalid relative default %q", value))
+	}
+}
+
+type Time struct {
+	target   *time.Time
+	envvar   *env
+	defval   *time.Time
+	relative *string // default that's relative to the clock
+	source   Source  // where the value came from
+}
+
+func (v *Time) Default(value time.Time) {
+	v.defval = &value
+	v.relative = nil
+}
+
+// DefaultRelative sets a default that's resolved against the clock when
+// parsing, e.g. "24h" for a day ago. It's shown as-is in the help.
+func (v *Time) DefaultRelative(value string) {
+	checkRelative(value)
+	v.relative = &value
+	v.defval = nil
+}
+
+// Source returns where the value came from
+func (v *Time) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Time) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Time) IsSet() bool {
+	return v.source.isSet()
+}
+
+type timeValue struct {
+	key     string
+	inner   *Time
+	config  *config // resolves relative times
+	layouts []string
+	set     bool
+}
+
+var _ value = (*timeValue)(nil)
+
+func (v *timeValue) optional() bool {
+	return false
+}
+
+func (v *timeValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *timeValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *timeValue) placeholder() string {
+	return "time"
+}
+
+func (v *timeValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *timeValue) Default() (string, bool) {
+	if v.inner.relative != nil {
+		return *v.inner.relative, true
+	} else if v.inner.defval == nil {
+		return "", false
+	}
+	return formatTime(*v.inner.defval), true
+}
+
+func (v *timeValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = *v.inner.defval
+		return nil
+	} else if v.inner.relative != nil {
+		t, err := parseTime(v.config.clock(), v.layouts, *v.inner.relative)
+		if err != nil {
+			return err
+		}
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = t
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *timeValue) Set(val string) error {
+	t, err := parseTime(v.config.clock(), v.layouts, val)
+	if err != nil {
+		return fmt.Errorf("%s: expected a time like 2006-01-02, 2h or now but got %q", v.key, val)
+	}
+	*v.inner.target = t
+	v.set = true
+	return nil
+}
+
+func (v *timeValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatTime(*v.inner.target)
+	} else if v.hasDefault() {
+		return formatTime(*v.inner.defval)
+	} else if v.inner.relative != nil {
+		return *v.inner.relative
+	}
+	return ""
+}
+
+type OptionalTime struct {
+	target   **time.Time
+	envvar   *env
+	defval   *time.Time
+	relative *string // default that's relative to the clock
+	source   Source  // where the value came from
+}
+
+func (v *OptionalTime) Default(value time.Time) {
+	v.defval = &value
+	v.relative = nil
+}
+
+// DefaultRelative sets a default that's resolved against the clock when
+// parsing, e.g. "24h" for a day ago. It's shown as-is in the help.
+func (v *OptionalTime) DefaultRelative(value string) {
+	checkRelative(value)
+	v.relative = &value
+	v.defval = nil
+}
+
+// Source returns where the value came from
+func (v *OptionalTime) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalTime) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalTime) IsSet() bool {
+	return v.source.isSet()
+}
+
+type optionalTimeValue struct {
+	key     string
+	inner   *OptionalTime
+	config  *config // resolves relative times
+	layouts []string
+	set     bool
+}
+
+var _ value = (*optionalTimeValue)(nil)
+
+func (v *optionalTimeValue) optional() bool {
+	return true
+}
+
+func (v *optionalTimeValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalTimeValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalTimeValue) placeholder() string {
+	return "time"
+}
+
+func (v *optionalTimeValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *optionalTimeValue) Default() (string, bool) {
+	if v.inner.relative != nil {
+		return *v.inner.relative, true
+	} else if v.inner.defval == nil {
+		return "", false
+	}
+	return formatTime(*v.inner.defval), true
+}
+
+func (v *optionalTimeValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		return v.Set(value)
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = v.inner.defval
+		return nil
+	} else if v.inner.relative != nil {
+		t, err := parseTime(v.config.clock(), v.layouts, *v.inner.relative)
+		if err != nil {
+			return err
+		}
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = &t
+		return nil
+	}
+	return nil
+}
+
+func (v *optionalTimeValue) Set(val string) error {
+	t, err := parseTime(v.config.clock(), v.layouts, val)
+	if err != nil {
+		return fmt.Errorf("%s: expected a time like 2006-01-02, 2h or now but got %q", v.key, val)
+	}
+	*v.inner.target = &t
+	v.set = true
+	return nil
+}
+
+func (v *optionalTimeValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatTime(**v.inner.target)
+	} else if v.hasDefault() {
+		return formatTime(*v.inner.defval)
+	} else if v.inner.relative != nil {
+		return *v.inner.relative
+	}
+	return ""
+}
+
+type Times struct {
+	target   *[]time.Time
+	envvar   *env
+	defval   *[]time.Time
+	optional bool
+	source   Source // where the value came from
+}
+
+func (v *Times) Default(values ...time.Time) {
+	v.defval = &values
+}
+
+// Source returns where the value came from
+func (v *Times) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Times) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Times) IsSet() bool {
+	return v.source.isSet()
+}
+
+type timesValue struct {
+	key     string
+	inner   *Times
+	config  *config // resolves relative times
+	layouts []string
+	set     bool
+}
+
+var _ value = (*timesValue)(nil)
+
+func (v *timesValue) optional() bool {
+	return v.inner.optional
+}
+
+func (v *timesValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *timesValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *timesValue) placeholder() string {
+	return "time"
+}
+
+func (v *timesValue) verify() error {
+	if v.set {
+		return nil
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
+		fields, err := shellquote.Split(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a list of times but got %q", v.key, value)
+		}
+		for _, val := range fields {
+			if err := v.Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
+		*v.inner.target = *v.inner.defval
+		return nil
+	} else if v.inner.optional {
+		return nil
+	}
+	return &missingInputError{v.key, v.inner.envvar}
+}
+
+func (v *timesValue) hasDefault() bool {
+	return v.inner.defval != nil
+}
+
+func (v *timesValue) Default() (string, bool) {
+	if v.inner.defval == nil {
+		return "", false
+	}
+	if len(*v.inner.defval) == 0 {
+		return "[]", true
+	}
+	return formatTimes(*v.inner.defval), true
+}
+
+func (v *timesValue) Set(val string) error {
+	t, err := parseTime(v.config.clock(), v.layouts, val)
+	if err != nil {
+		return fmt.Errorf("%s: expected a time like 2006-01-02, 2h or now but got %q", v.key, val)
+	}
+	*v.inner.target = append(*v.inner.target, t)
+	v.set = true
+	return nil
+}
+
+func (v *timesValue) String() string {
+	if v.inner == nil {
+		return ""
+	} else if v.set {
+		return formatTimes(*v.inner.target)
+	} else if v.hasDefault() {
+		return formatTimes(*v.inner.defval)
+	}
+	return ""
+}
+
+func formatTimes(times []time.Time) string {
+	strs := make([]string, len(times))
+	for i, t := range times {
+		strs[i] = formatTime(t)
+	}
+	return strings.Join(strs, ", ")
+}
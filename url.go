@@ -10,14 +10,32 @@ import (
 
 type Url struct {
 	target *url.URL
-	envvar *string
+	envvar *env
 	defval *url.URL
+	source Source // where the value came from
 }
 
 func (v *Url) Default(value url.URL) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *Url) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Url) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Url) IsSet() bool {
+	return v.source.isSet()
+}
+
 type urlValue struct {
 	key   string
 	inner *Url
@@ -30,6 +48,19 @@ func (v *urlValue) optional() bool {
 	return false
 }
 
+func (v *urlValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *urlValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+}
+
+func (v *urlValue) placeholder() string {
+	return "url"
+}
+
 func (v *urlValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -44,9 +75,13 @@ func (v *urlValue) Default() (string, bool) {
 func (v *urlValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	}
@@ -76,14 +111,32 @@ func (v *urlValue) String() string {
 
 type OptionalUrl struct {
 	target **url.URL
-	envvar *string
+	envvar *env
 	defval *url.URL
+	source Source // where the value came from
 }
 
 func (v *OptionalUrl) Default(value url.URL) {
 	v.defval = &value
 }
 
+// Source returns where the value came from
+func (v *OptionalUrl) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *OptionalUrl) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *OptionalUrl) IsSet() bool {
+	return v.source.isSet()
+}
+
 type optionalUrlValue struct {
 	key   string
 	inner *OptionalUrl
@@ -96,6 +149,22 @@ func (v *optionalUrlValue) optional() bool {
 	return true
 }
 
+func (v *optionalUrlValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *optionalUrlValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = nil
+	}
+}
+
+func (v *optionalUrlValue) placeholder() string {
+	return "url"
+}
+
 func (v *optionalUrlValue) hasDefault() bool {
 	return v.inner.defval != nil
 }
@@ -110,9 +179,13 @@ func (v *optionalUrlValue) Default() (string, bool) {
 func (v *optionalUrlValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		return v.Set(value)
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = v.inner.defval
 		return nil
 	}
@@ -142,15 +215,33 @@ func (v *optionalUrlValue) String() string {
 
 type Urls struct {
 	target   *[]*url.URL
-	envvar   *string
+	envvar   *env
 	defval   *[]*url.URL
 	optional bool
+	source   Source // where the value came from
 }
 
 func (v *Urls) Default(values ...*url.URL) {
 	v.defval = &values
 }
 
+// Source returns where the value came from
+func (v *Urls) Source() Source {
+	return v.source
+}
+
+// Config sets the value from a config file. It's parsed like the environment
+// variable, which overrides it along with the command line.
+func (v *Urls) Config(value string) {
+	v.envvar.configured = &value
+}
+
+// IsSet is true when the value was passed on the command line or came from an
+// environment variable or a config file
+func (v *Urls) IsSet() bool {
+	return v.source.isSet()
+}
+
 type urlsValue struct {
 	key   string
 	inner *Urls
@@ -163,10 +254,29 @@ func (v *urlsValue) optional() bool {
 	return v.inner.optional
 }
 
+func (v *urlsValue) source() *Source {
+	return &v.inner.source
+}
+
+func (v *urlsValue) reset() {
+	v.set = false
+	v.inner.source = Source{}
+	if v.inner.target != nil {
+		*v.inner.target = (*v.inner.target)[:0:0]
+	}
+}
+
+func (v *urlsValue) placeholder() string {
+	return "url"
+}
+
 func (v *urlsValue) verify() error {
 	if v.set {
 		return nil
-	} else if value, ok := lookupEnv(v.inner.envvar); ok {
+	} else if value, source, ok, err := lookupEnv(v.inner.envvar); err != nil {
+		return err
+	} else if ok {
+		v.inner.source = source
 		fields, err := shellquote.Split(value)
 		if err != nil {
 			return fmt.Errorf("%s: expected a list of URLs but got %q", v.key, value)
@@ -178,6 +288,7 @@ func (v *urlsValue) verify() error {
 		}
 		return nil
 	} else if v.hasDefault() {
+		v.inner.source = Source{Kind: SourceDefault}
 		*v.inner.target = *v.inner.defval
 		return nil
 	} else if v.inner.optional {
@@ -1,13 +1,12 @@
 package cli
 
 import (
-	"bytes"
 	_ "embed"
 	"flag"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
-	"text/tabwriter"
 	"text/template"
 )
 
@@ -21,7 +20,11 @@ var usageTemplate string
 var defaultUsage = template.Must(template.New("usage").Funcs(colors).Parse(usageTemplate))
 
 type usage struct {
-	cmd *command
+	cmd     *command
+	width   int
+	verbose bool
+	color   bool
+	theme   Theme
 }
 
 func (u *usage) Name() string {
@@ -56,48 +59,57 @@ func formatRestArgsUsage(name string) string {
 
 func (u *usage) Usage() string {
 	out := new(strings.Builder)
-	if len(u.cmd.flags) > 0 {
+	if slices.ContainsFunc(u.cmd.flags, isVisibleFlag) {
 		out.WriteString(" ")
-		out.WriteString(dim())
-		out.WriteString("[flags]")
-		out.WriteString(reset())
+		out.WriteString(u.paint(u.theme.Hint, "[flags]"))
 	}
 	if u.cmd.run != nil && (len(u.cmd.args) > 0 || u.cmd.restArgs != nil) {
 		for _, arg := range u.cmd.args {
+			if arg.hidden || (arg.deprecated != nil && !u.verbose) {
+				continue
+			}
 			out.WriteString(" ")
-			out.WriteString(dim())
-			out.WriteString(formatFixedArgUsage(arg.name, arg.value))
-			out.WriteString(reset())
+			out.WriteString(u.paint(u.theme.Hint, formatFixedArgUsage(arg.name, arg.value)))
 		}
 		if u.cmd.restArgs != nil {
 			out.WriteString(" ")
-			out.WriteString(dim())
-			out.WriteString(formatRestArgsUsage(u.cmd.restArgs.name))
-			out.WriteString(reset())
+			out.WriteString(u.paint(u.theme.Hint, formatRestArgsUsage(u.cmd.restArgs.name)))
 		}
 	} else if len(u.cmd.commands) > 0 {
 		out.WriteString(" ")
-		out.WriteString(dim())
-		out.WriteString("[command]")
-		out.WriteString(reset())
+		out.WriteString(u.paint(u.theme.Hint, "[command]"))
 	}
 	return out.String()
 }
 
 func (u *usage) Description() string {
+	if u.cmd.long != "" {
+		return indentText(u.cmd.long, 4, u.width)
+	}
 	return u.cmd.help
 }
 
+// Verbose is true when the usage was requested with --help
+func (u *usage) Verbose() bool {
+	return u.verbose
+}
+
 func (u *usage) Args() (args usageArgs) {
 	for _, arg := range u.cmd.args {
+		if arg.hidden || (arg.deprecated != nil && !u.verbose) {
+			continue
+		}
 		args = append(args, &usageArg{
-			name:  arg.name,
-			help:  arg.help,
-			value: arg.value,
+			u:          u,
+			name:       arg.name,
+			help:       arg.help,
+			value:      arg.value,
+			deprecated: arg.deprecated,
 		})
 	}
 	if u.cmd.restArgs != nil {
 		args = append(args, &usageArg{
+			u:        u,
 			name:     u.cmd.restArgs.name,
 			help:     u.cmd.restArgs.help,
 			value:    u.cmd.restArgs.value,
@@ -108,10 +120,12 @@ func (u *usage) Args() (args usageArgs) {
 }
 
 type usageArg struct {
-	name     string
-	help     string
-	value    value
-	variadic bool
+	u          *usage
+	name       string
+	help       string
+	value      value
+	variadic   bool
+	deprecated *string
 }
 
 func (a *usageArg) Key() string {
@@ -122,7 +136,9 @@ func (a *usageArg) Key() string {
 }
 
 func (a *usageArg) Suffix() string {
-	if a.value == nil {
+	if a.deprecated != nil {
+		return " (" + deprecatedSuffix(a.deprecated) + ")"
+	} else if a.value == nil {
 		return ""
 	}
 	if def, ok := a.value.Default(); ok {
@@ -136,63 +152,95 @@ func (a *usageArg) Suffix() string {
 type usageArgs []*usageArg
 
 func (args usageArgs) Usage() (string, error) {
-	buf := new(bytes.Buffer)
-	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
-	for _, arg := range args {
-		tw.Write([]byte("\t\t"))
-		tw.Write([]byte(arg.Key()))
-		if arg.help != "" {
-			tw.Write([]byte("\t" + dim()))
-			tw.Write([]byte(arg.help))
-			tw.Write([]byte(arg.Suffix()))
-			tw.Write([]byte(reset()))
-		}
-		tw.Write([]byte("\n"))
+	if len(args) == 0 {
+		return "", nil
 	}
-	if err := tw.Flush(); err != nil {
-		return "", err
+	rows := make([]row, len(args))
+	for i, arg := range args {
+		u := arg.u
+		rows[i].key = u.paint(u.theme.Arg, arg.Key())
+		rows[i].help = arg.help
+		rows[i].suffix = strings.TrimSpace(arg.Suffix())
 	}
-	return strings.TrimSpace(buf.String()), nil
+	return formatRows(rows, 4, args[0].u), nil
 }
 
 func (u *usage) Commands() (commands usageCommands) {
-	seen := make(map[*command]bool)
-	for _, cmd := range u.cmd.commands {
-		if cmd.advanced || cmd.hidden {
+	for _, cmd := range sortedCommands(u.cmd) {
+		if cmd.advanced || cmd.hidden || (cmd.deprecated != nil && !u.verbose) {
 			continue
 		}
-		// Handle aliases by only showing the command once
-		if seen[cmd] {
-			continue
-		}
-		seen[cmd] = true
-		commands = append(commands, &usageCommand{cmd})
+		commands = append(commands, &usageCommand{u, cmd})
 	}
-	// Sort by name
-	sort.Slice(commands, func(i, j int) bool {
-		return commands[i].c.name < commands[j].c.name
-	})
 	return commands
 }
 
 func (u *usage) Advanced() (commands usageCommands) {
-	for _, cmd := range u.cmd.commands {
-		if !cmd.advanced || cmd.hidden {
+	for _, cmd := range sortedCommands(u.cmd) {
+		if !cmd.advanced || cmd.hidden || (cmd.deprecated != nil && !u.verbose) {
 			continue
 		}
-		commands = append(commands, &usageCommand{cmd})
+		commands = append(commands, &usageCommand{u, cmd})
 	}
-	// Sort by name
-	sort.Slice(commands, func(i, j int) bool {
-		return commands[i].c.name < commands[j].c.name
-	})
 	return commands
 }
 
+// Flags returns all the visible flags, including the grouped and advanced flags
 func (u *usage) Flags() (flags usageFlags) {
-	flags = make(usageFlags, len(u.cmd.flags))
-	for i, flag := range u.cmd.flags {
-		flags[i] = &usageFlag{flag}
+	return u.filterFlags(func(flag *Flag) bool {
+		return true
+	})
+}
+
+// UngroupedFlags returns the flags that aren't in a group and aren't advanced
+func (u *usage) UngroupedFlags() (flags usageFlags) {
+	return u.groupFlags("")
+}
+
+// AdvancedFlags returns the advanced flags, regardless of their group
+func (u *usage) AdvancedFlags() (flags usageFlags) {
+	return u.filterFlags(func(flag *Flag) bool {
+		return flag.advanced
+	})
+}
+
+func (u *usage) FlagGroups() (groups []*usageFlagGroup) {
+	for _, name := range u.cmd.flagGroups() {
+		flags := u.groupFlags(name)
+		if len(flags) == 0 {
+			continue
+		}
+		groups = append(groups, &usageFlagGroup{name, flags})
+	}
+	return groups
+}
+
+type usageFlagGroup struct {
+	name  string
+	flags usageFlags
+}
+
+func (g *usageFlagGroup) Name() string {
+	return g.name
+}
+
+func (g *usageFlagGroup) Flags() usageFlags {
+	return g.flags
+}
+
+func (u *usage) groupFlags(group string) (flags usageFlags) {
+	return u.filterFlags(func(flag *Flag) bool {
+		return !flag.advanced && flag.group == group
+	})
+}
+
+// filterFlags returns the sorted flags that are visible and match
+func (u *usage) filterFlags(match func(flag *Flag) bool) (flags usageFlags) {
+	for _, flag := range u.cmd.flags {
+		if !isVisibleFlag(flag) || (flag.deprecated != nil && !u.verbose) || !match(flag) {
+			continue
+		}
+		flags = append(flags, &usageFlag{u, flag})
 	}
 	// Sort by name
 	sort.Slice(flags, func(i, j int) bool {
@@ -207,43 +255,63 @@ func (u *usage) Flags() (flags usageFlags) {
 }
 
 type usageCommand struct {
+	u *usage
 	c *command
 }
 
 type usageCommands []*usageCommand
 
 func (cmds usageCommands) Usage() (string, error) {
-	buf := new(bytes.Buffer)
-	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
-	for _, cmd := range cmds {
-		tw.Write([]byte("\t\t" + cmd.c.name))
-		if cmd.c.help != "" {
-			tw.Write([]byte("\t" + dim()))
-			tw.Write([]byte(cmd.c.help))
-			if cmd.c.alias != "" {
-				tw.Write([]byte(" (alias: " + cmd.c.alias + ")"))
-			}
-			tw.Write([]byte(reset()))
-		}
-		tw.Write([]byte("\n"))
+	if len(cmds) == 0 {
+		return "", nil
+	}
+	rows := make([]row, len(cmds))
+	for i, cmd := range cmds {
+		u := cmd.u
+		rows[i].key = u.paint(u.theme.Command, cmd.c.name)
+		rows[i].help = cmd.c.help
+		rows[i].suffix = cmd.Suffix()
+	}
+	return formatRows(rows, 4, cmds[0].u), nil
+}
+
+func (u *usageCommand) Suffix() string {
+	attrs := []string{}
+	if len(u.c.aliases) == 1 {
+		attrs = append(attrs, "alias: "+u.c.aliases[0])
+	} else if len(u.c.aliases) > 1 {
+		attrs = append(attrs, "aliases: "+strings.Join(u.c.aliases, ", "))
 	}
-	if err := tw.Flush(); err != nil {
-		return "", err
+	if u.c.deprecated != nil {
+		attrs = append(attrs, deprecatedSuffix(u.c.deprecated))
 	}
-	return strings.TrimSpace(buf.String()), nil
+	if len(attrs) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(attrs, ", ") + ")"
 }
 
 type usageFlag struct {
+	u *usage
 	f *Flag
 }
 
 func (u *usageFlag) Suffix() string {
 	attrs := []string{}
-	if u.f.env != nil {
-		attrs = append(attrs, "or $"+*u.f.env)
+	if u.f.deprecated != nil {
+		attrs = append(attrs, deprecatedSuffix(u.f.deprecated))
+	}
+	if u.f.env.file {
+		attrs = append(attrs, "or --"+u.f.fileKey())
+	}
+	if hasEnv(u.f.env) {
+		attrs = append(attrs, "or "+u.f.env.String())
+		if u.f.env.readsFiles() {
+			attrs = append(attrs, "or "+u.f.env.String()+"_FILE")
+		}
 	}
 	if def, ok := u.f.value.Default(); ok {
-		attrs = append(attrs, "default:"+strconv.Quote(def))
+		attrs = append(attrs, "default:"+strconv.Quote(redact(def, u.f.secret)))
 	} else if u.f.value.optional() {
 		attrs = append(attrs, "optional")
 	}
@@ -262,29 +330,88 @@ func (u *usageFlag) Suffix() string {
 	return out.String()
 }
 
+// Placeholder describes the flag's value, e.g. <duration> or <json|yaml>
+func (u *usageFlag) Placeholder() string {
+	if u.f.placeholder != nil {
+		return *u.f.placeholder
+	} else if u.f.value == nil {
+		return ""
+	} else if placeholder := u.f.value.placeholder(); placeholder != "" {
+		return "<" + placeholder + ">"
+	}
+	return ""
+}
+
 type usageFlags []*usageFlag
 
 func (flags usageFlags) Usage() (string, error) {
-	buf := new(bytes.Buffer)
-	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
-	for _, flag := range flags {
-		tw.Write([]byte("\t\t"))
+	if len(flags) == 0 {
+		return "", nil
+	}
+	rows := make([]row, len(flags))
+	for i, flag := range flags {
+		u := flag.u
+		key := ""
 		if flag.f.short != "" {
-			tw.Write([]byte("-" + string(flag.f.short) + ", "))
+			key = "-" + flag.f.short + ", "
+		}
+		key += "--" + flag.f.name
+		for _, alias := range flag.f.aliases {
+			key += ", --" + alias
+		}
+		if placeholder := flag.Placeholder(); placeholder != "" {
+			key += " " + placeholder
 		}
-		tw.Write([]byte("--" + flag.f.name))
-		if flag.f.help != "" {
-			tw.Write([]byte("\t" + dim()))
-			tw.Write([]byte(flag.f.help))
-			tw.Write([]byte(flag.Suffix()))
-			tw.Write([]byte(reset()))
+		rows[i].key = u.paint(u.theme.Flag, key)
+		rows[i].help = flag.f.help
+		rows[i].suffix = strings.TrimSpace(flag.Suffix())
+		if u.verbose {
+			rows[i].long = flag.f.long
 		}
-		tw.Write([]byte("\n"))
 	}
-	if err := tw.Flush(); err != nil {
-		return "", err
+	return formatRows(rows, 4, flags[0].u), nil
+}
+
+func (u *usage) Examples() (examples usageExamples) {
+	for _, example := range u.cmd.examples {
+		examples = append(examples, &usageExample{u, example})
 	}
-	return strings.TrimSpace(buf.String()), nil
+	return examples
+}
+
+type usageExample struct {
+	u       *usage
+	example *example
+}
+
+func (e *usageExample) Command() string {
+	return joinCommand(e.u.cmd.full, e.example.cmdline)
+}
+
+func (e *usageExample) Description() string {
+	return e.example.help
+}
+
+type usageExamples []*usageExample
+
+func (examples usageExamples) Usage() string {
+	out := new(strings.Builder)
+	for i, example := range examples {
+		if i > 0 {
+			out.WriteString("\n\n    ")
+		}
+		u := example.u
+		if example.example.help != "" {
+			out.WriteString(u.paint(u.theme.Description, "# "+example.example.help))
+			out.WriteString("\n    ")
+		}
+		out.WriteString(u.paint(u.theme.Hint, "$") + " " + example.Command())
+	}
+	return out.String()
+}
+
+func isVisibleFlag(flag *Flag) bool {
+	return !flag.hidden
 }
 
 func hasShort(flag *usageFlag) bool {
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"text/template"
+)
+
+//go:embed version.gotext
+var versionTemplate string
+
+var defaultVersion = template.Must(template.New("version").Funcs(colors).Parse(versionTemplate))
+
+// Version adds a --version flag to the root command that prints the version.
+// When the version is empty, the module version and VCS information from the
+// build are used.
+func (c *CLI) Version(version string) *CLI {
+	c.config.versionConfig().version = version
+	flag := c.root.Flag("version", "print the version")
+	flag.Bool(&c.config.version.requested).Default(false)
+	// Subcommands don't inherit --version
+	flag.local = true
+	return c
+}
+
+// VersionCommand adds a "version" command that prints the version.
+func (c *CLI) VersionCommand() *CLI {
+	if _, ok := c.root.commands["version"]; ok {
+		panic(fmt.Sprintf("cli: cannot add a version command to %q because it already exists", c.root.full))
+	}
+	c.config.versionConfig()
+	// Don't inherit the root flags, since they might be required
+	cmd := newCommand(c.config, c.root, []*Flag{}, "version", c.root.full+" version", "print the version")
+	// Printing the version shouldn't run the app's middleware
+	cmd.isolated = true
+	c.root.commands["version"] = cmd
+	cmd.Run(func(ctx context.Context) error {
+		return c.root.printVersion()
+	})
+	return c
+}
+
+// VersionTemplate customizes the version output
+func (c *CLI) VersionTemplate(template *template.Template) *CLI {
+	c.config.versionConfig().template = template
+	return c
+}
+
+func (c *config) versionConfig() *versionConfig {
+	if c.version == nil {
+		c.version = &versionConfig{template: defaultVersion}
+	}
+	return c.version
+}
+
+type versionConfig struct {
+	version   string
+	template  *template.Template
+	requested bool // set by --version
+}
+
+// versionRequested is true when --version was passed. It's reset right away,
+// so the next parse doesn't print the version again.
+func (c *command) versionRequested() bool {
+	if c.config.version == nil || !c.config.version.requested {
+		return false
+	}
+	c.config.version.requested = false
+	return true
+}
+
+func (c *command) printVersion() error {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	tpl, err := c.config.version.template.Clone()
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(colorFuncs(c.config.theme, c.config.colorEnabled(c.config.stdout)))
+	out := new(strings.Builder)
+	if err := tpl.Execute(out, readVersion(root.name, c.config.version.version)); err != nil {
+		return err
+	}
+	_, err = c.config.stdout.Write([]byte(strings.TrimRight(out.String(), "\n") + "\n"))
+	return err
+}
+
+// versionInfo is passed to the version template
+type versionInfo struct {
+	Name      string
+	Version   string
+	Revision  string
+	Dirty     bool
+	Time      string
+	GoVersion string
+}
+
+func readVersion(name, version string) *versionInfo {
+	info := &versionInfo{Name: name, Version: version}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		if info.Version == "" {
+			info.Version = "(devel)"
+		}
+		return info
+	}
+	info.GoVersion = build.GoVersion
+	if info.Version == "" {
+		info.Version = build.Main.Version
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+			if len(info.Revision) > 12 {
+				info.Revision = info.Revision[:12]
+			}
+		case "vcs.modified":
+			info.Dirty = setting.Value == "true"
+		case "vcs.time":
+			info.Time = setting.Value
+		}
+	}
+	return info
+}
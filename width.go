@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const defaultWidth = 80
+
+// Width sets the width used to wrap the help output. By default the width of
+// the terminal is used, falling back to $COLUMNS and then 80.
+func (c *CLI) Width(width int) *CLI {
+	c.config.width = width
+	return c
+}
+
+// terminalWidth of the writer
+func (c *config) terminalWidth(w io.Writer) int {
+	if c.width > 0 {
+		return c.width
+	}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return defaultWidth
+}
+
+// The minimum width of the description column before we stop wrapping
+const minWrapWidth = 20
+
+type row struct {
+	key    string
+	help   string
+	suffix string // attributes shown after the help, e.g. (default:"info")
+	long   string // paragraphs shown below the help
+}
+
+func (r *row) hasHelp() bool {
+	return r.help != "" || r.long != ""
+}
+
+// lines returns the styled lines of the help column
+func (r *row) lines(u *usage, width int) (lines []string) {
+	if r.help != "" {
+		lines = wrapStyled(u, width, &span{r.help, u.theme.Description}, &span{r.suffix, u.theme.Default})
+	}
+	for i, line := range reflow(r.long, width) {
+		if i == 0 && len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, u.paint(u.theme.Description, line))
+	}
+	return lines
+}
+
+// formatRows lays out the rows in two columns, wrapping the help column to the
+// width with a hanging indent. The first line isn't indented because the
+// template provides the indentation.
+func formatRows(rows []row, indent int, u *usage) string {
+	keyWidth := 0
+	for _, row := range rows {
+		if !row.hasHelp() {
+			continue
+		}
+		keyWidth = max(keyWidth, stringWidth(row.key))
+	}
+	column := indent + keyWidth + 2
+	out := new(strings.Builder)
+	for i, row := range rows {
+		if i > 0 {
+			out.WriteString("\n")
+			out.WriteString(strings.Repeat(" ", indent))
+		}
+		out.WriteString(row.key)
+		if !row.hasHelp() {
+			continue
+		}
+		out.WriteString(strings.Repeat(" ", keyWidth-stringWidth(row.key)+2))
+		for j, line := range row.lines(u, u.width-column) {
+			if j > 0 {
+				out.WriteString("\n")
+			}
+			if line == "" {
+				continue
+			} else if j > 0 {
+				out.WriteString(strings.Repeat(" ", column))
+			}
+			out.WriteString(line)
+		}
+	}
+	return out.String()
+}
+
+type span struct {
+	text  string
+	style Style
+}
+
+// wrapStyled wraps the spans into lines that fit within the width. Adjacent
+// words with the same style are painted together.
+func wrapStyled(u *usage, width int, spans ...*span) (lines []string) {
+	var words []*span
+	for _, s := range spans {
+		for _, word := range strings.Fields(s.text) {
+			words = append(words, &span{word, s.style})
+		}
+	}
+	var line []*span
+	lineWidth := 0
+	for _, word := range words {
+		wordWidth := stringWidth(word.text)
+		if width >= minWrapWidth && lineWidth > 0 && lineWidth+1+wordWidth > width {
+			lines = append(lines, paintLine(u, line))
+			line = nil
+			lineWidth = 0
+		}
+		if lineWidth > 0 {
+			lineWidth++
+		}
+		line = append(line, word)
+		lineWidth += wordWidth
+	}
+	return append(lines, paintLine(u, line))
+}
+
+func paintLine(u *usage, words []*span) string {
+	out := new(strings.Builder)
+	for i := 0; i < len(words); {
+		j := i + 1
+		for j < len(words) && words[j].style == words[i].style {
+			j++
+		}
+		if i > 0 {
+			out.WriteString(" ")
+		}
+		texts := make([]string, 0, j-i)
+		for _, word := range words[i:j] {
+			texts = append(texts, word.text)
+		}
+		out.WriteString(u.paint(words[i].style, strings.Join(texts, " ")))
+		i = j
+	}
+	return out.String()
+}
+
+// indentText reflows the text to the width, indenting every line but the first
+// because the template provides the indentation.
+func indentText(text string, indent, width int) string {
+	out := new(strings.Builder)
+	for i, line := range reflow(text, width-indent) {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if i > 0 && line != "" {
+			out.WriteString(strings.Repeat(" ", indent))
+		}
+		out.WriteString(line)
+	}
+	return out.String()
+}
+
+// reflow the paragraphs in the text to the width, separating paragraphs with an
+// empty line. Indented lines are considered preformatted and left as-is.
+func reflow(text string, width int) (lines []string) {
+	for i, paragraph := range paragraphs(text) {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		if isPreformatted(paragraph) {
+			lines = append(lines, paragraph...)
+			continue
+		}
+		lines = append(lines, wrap(strings.Join(paragraph, " "), width)...)
+	}
+	return lines
+}
+
+// paragraphs splits the dedented text into paragraphs of lines
+func paragraphs(text string) (paragraphs [][]string) {
+	var paragraph []string
+	for _, line := range strings.Split(dedent(text), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(paragraph) > 0 {
+				paragraphs = append(paragraphs, paragraph)
+				paragraph = nil
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	if len(paragraph) > 0 {
+		paragraphs = append(paragraphs, paragraph)
+	}
+	return paragraphs
+}
+
+func isPreformatted(paragraph []string) bool {
+	for _, line := range paragraph {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// dedent removes the common leading whitespace from every line
+func dedent(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	margin := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if margin < 0 || indent < margin {
+			margin = indent
+		}
+	}
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = strings.TrimRight(line[margin:], " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrap the text into lines that fit within the width
+func wrap(text string, width int) (lines []string) {
+	if width < minWrapWidth {
+		return []string{text}
+	}
+	line := new(strings.Builder)
+	lineWidth := 0
+	for _, word := range strings.Fields(text) {
+		wordWidth := stringWidth(word)
+		if lineWidth > 0 && lineWidth+1+wordWidth > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			lineWidth = 0
+		}
+		if lineWidth > 0 {
+			line.WriteString(" ")
+			lineWidth++
+		}
+		line.WriteString(word)
+		lineWidth += wordWidth
+	}
+	return append(lines, line.String())
+}
+
+// stringWidth returns the number of columns the string occupies in the
+// terminal, ignoring escape codes
+func stringWidth(s string) (width int) {
+	for i := 0; i < len(s); {
+		// Skip over escape codes like \033[37m
+		if s[i] == '\033' && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// runeWidth returns the number of columns the rune occupies in the terminal
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Variation_Selector):
+		return 0
+	case unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// wide contains the East Asian wide and fullwidth ranges, as well as the emoji
+// that are presented as wide by default
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2329, Hi: 0x232a, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23ec, Stride: 1},
+		{Lo: 0x23f0, Hi: 0x23f0, Stride: 1},
+		{Lo: 0x23f3, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x25fd, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x267f, Hi: 0x267f, Stride: 1},
+		{Lo: 0x2693, Hi: 0x2693, Stride: 1},
+		{Lo: 0x26a1, Hi: 0x26a1, Stride: 1},
+		{Lo: 0x26aa, Hi: 0x26ab, Stride: 1},
+		{Lo: 0x26bd, Hi: 0x26be, Stride: 1},
+		{Lo: 0x26c4, Hi: 0x26c5, Stride: 1},
+		{Lo: 0x26ce, Hi: 0x26ce, Stride: 1},
+		{Lo: 0x26d4, Hi: 0x26d4, Stride: 1},
+		{Lo: 0x26ea, Hi: 0x26ea, Stride: 1},
+		{Lo: 0x26f2, Hi: 0x26f3, Stride: 1},
+		{Lo: 0x26f5, Hi: 0x26f5, Stride: 1},
+		{Lo: 0x26fa, Hi: 0x26fa, Stride: 1},
+		{Lo: 0x26fd, Hi: 0x26fd, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270a, Hi: 0x270b, Stride: 1},
+		{Lo: 0x2728, Hi: 0x2728, Stride: 1},
+		{Lo: 0x274c, Hi: 0x274c, Stride: 1},
+		{Lo: 0x274e, Hi: 0x274e, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2757, Hi: 0x2757, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x27b0, Hi: 0x27b0, Stride: 1},
+		{Lo: 0x27bf, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b50, Stride: 1},
+		{Lo: 0x2b55, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xa960, Hi: 0xa97f, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe10, Hi: 0xfe19, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe6f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x16fe0, Hi: 0x16fe4, Stride: 1},
+		{Lo: 0x17000, Hi: 0x18cff, Stride: 1},
+		{Lo: 0x1b000, Hi: 0x1b2ff, Stride: 1},
+		{Lo: 0x1f004, Hi: 0x1f004, Stride: 1},
+		{Lo: 0x1f0cf, Hi: 0x1f0cf, Stride: 1},
+		{Lo: 0x1f18e, Hi: 0x1f18e, Stride: 1},
+		{Lo: 0x1f191, Hi: 0x1f19a, Stride: 1},
+		{Lo: 0x1f200, Hi: 0x1f202, Stride: 1},
+		{Lo: 0x1f210, Hi: 0x1f23b, Stride: 1},
+		{Lo: 0x1f240, Hi: 0x1f248, Stride: 1},
+		{Lo: 0x1f250, Hi: 0x1f251, Stride: 1},
+		{Lo: 0x1f260, Hi: 0x1f265, Stride: 1},
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f7e0, Hi: 0x1f7eb, Stride: 1},
+		{Lo: 0x1f90c, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x1fa70, Hi: 0x1faff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+func isWide(r rune) bool {
+	return r >= 0x1100 && unicode.Is(wide, r)
+}